@@ -1,24 +1,80 @@
 package geobuf
 
 import (
+	"errors"
+
+	protobuf "github.com/golang/protobuf/proto"
+
 	"github.com/cairnapp/go-geobuf/pkg/decode"
-	"github.com/cairnapp/go-geobuf/pkg/geojson"
 	"github.com/cairnapp/go-geobuf/proto"
 )
 
+var ErrNoData = errors.New("geobuf: message has no geometry, feature or feature collection")
+
+// Decode is DecodeWithError for trusted input, panicking if msg is malformed.
+// A message with no geometry, feature or feature collection decodes to an
+// empty struct{}{}, as it always has, rather than panicking with ErrNoData.
 func Decode(msg *proto.Data) interface{} {
+	decoded, err := DecodeWithError(msg)
+	if err == ErrNoData {
+		return struct{}{}
+	}
+	if err != nil {
+		panic(err)
+	}
+	return decoded
+}
+
+// DecodeWithError decodes msg into a *geojson.Geometry, *geojson.Feature or
+// *geojson.FeatureCollection, validating it along the way. Malformed input
+// yields a *decode.FeatureError and/or *decode.FieldError describing where
+// decoding failed, wrapping one of the decode.Err* values.
+func DecodeWithError(msg *proto.Data) (interface{}, error) {
+	return DecodeWithOptions(msg)
+}
+
+// DecodeWithOptions is DecodeWithError with explicit decoding options.
+func DecodeWithOptions(msg *proto.Data, opts ...decode.DecodingOption) (interface{}, error) {
+	cfg := &decode.DecodingConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	switch v := msg.DataType.(type) {
 	case *proto.Data_Geometry_:
-		geo := v.Geometry
-		return decode.DecodeGeometry(geo, msg.Precision, msg.Dimensions)
+		geo, err := decode.DecodeGeometry(msg, v.Geometry, msg.Precision, msg.Dimensions, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return geo, nil
 	case *proto.Data_Feature_:
-		return decode.DecodeFeature(msg, v.Feature, msg.Precision, msg.Dimensions)
+		feature, err := decode.DecodeFeature(msg, v.Feature, msg.Precision, msg.Dimensions, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return feature, nil
 	case *proto.Data_FeatureCollection_:
-		collection := geojson.NewFeatureCollection()
-		for _, feature := range v.FeatureCollection.Features {
-			collection.Append(decode.DecodeFeature(msg, feature, msg.Precision, msg.Dimensions))
+		collection, err := decode.DecodeFeatureCollection(msg, v.FeatureCollection, msg.Precision, msg.Dimensions, cfg)
+		if err != nil {
+			return nil, err
 		}
-		return collection
+		return collection, nil
+	}
+	return nil, ErrNoData
+}
+
+// Unmarshal parses geobuf bytes and decodes them into a *geojson.Geometry,
+// *geojson.Feature or *geojson.FeatureCollection.
+func Unmarshal(buf []byte) (interface{}, error) {
+	return UnmarshalWithOptions(buf)
+}
+
+// UnmarshalWithOptions is Unmarshal with explicit decoding options, mirroring
+// DecodeWithOptions.
+func UnmarshalWithOptions(buf []byte, opts ...decode.DecodingOption) (interface{}, error) {
+	msg := &proto.Data{}
+	if err := protobuf.Unmarshal(buf, msg); err != nil {
+		return nil, err
 	}
-	return struct{}{}
+	return DecodeWithOptions(msg, opts...)
 }
@@ -1,14 +1,17 @@
 package geobuf_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 
 	. "github.com/cairnapp/go-geobuf"
+	"github.com/cairnapp/go-geobuf/pkg/decode"
 	"github.com/cairnapp/go-geobuf/pkg/geojson"
 	"github.com/cairnapp/go-geobuf/pkg/geometry"
+	"github.com/cairnapp/go-geobuf/proto"
 )
 
 func TestDecodePoint(t *testing.T) {
@@ -255,3 +258,465 @@ func TestDecodeFeatureCollection(t *testing.T) {
 		t.Errorf("Expected %+v, got %+v", p, decoded)
 	}
 }
+
+func TestDecodeWithErrorMalformed(t *testing.T) {
+	testCases := []struct {
+		Data     *proto.Data
+		Expected error
+		Message  string
+	}{
+		{
+			Data:     &proto.Data{},
+			Expected: ErrNoData,
+			Message:  ErrNoData.Error(),
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type: proto.Data_Geometry_Type(42),
+				}},
+			},
+			Expected: decode.ErrUnknownGeometryType,
+			Message:  "type: unknown geometry type: 42",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:   proto.Data_Geometry_POINT,
+					Coords: []int64{1},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "coords: lengths do not match coordinates: point has 1 coordinates, expected 2",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_MULTILINESTRING,
+					Lengths: []uint32{2, 3},
+					Coords:  []int64{1, 2, 3, 4},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: lengths describe more than the 4 coordinates",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_MULTIPOLYGON,
+					Lengths: []uint32{2, 1, 2},
+					Coords:  []int64{1, 2, 3, 4},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: missing ring count for polygon 1",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_MULTIPOLYGON,
+					Lengths: []uint32{0xFFFFFFF0},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: 4294967280 polygons, only 0 lengths left",
+		},
+		// Lengths whose sizes wrap around once multiplied by the dimension
+		{
+			Data: &proto.Data{
+				Dimensions: 16,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_POLYGON,
+					Lengths: []uint32{0xFFFFFFFF, 0xFFFFFFFF, 2},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: lengths describe more than the 0 coordinates",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 16,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_MULTILINESTRING,
+					Lengths: []uint32{0xFFFFFFFF, 0xFFFFFFFF, 2},
+					Coords:  []int64{1, 2},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: lengths describe more than the 2 coordinates",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 16,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_MULTIPOLYGON,
+					Lengths: []uint32{1, 3, 0xFFFFFFFF, 0xFFFFFFFF, 2},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: polygon 0 needs more than the 0 coordinates left",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 0x80000000,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_POLYGON,
+					Lengths: []uint32{0xFFFFFFFF, 0xFFFFFFFF, 2},
+				}},
+			},
+			Expected: decode.ErrInvalidDimension,
+			Message:  "dimensions: invalid dimension: 2147483648, at most 16 are supported",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Geometry_{Geometry: &proto.Data_Geometry{
+					Type:    proto.Data_Geometry_POLYGON,
+					Lengths: []uint32{0},
+				}},
+			},
+			Expected: decode.ErrInvalidLengths,
+			Message:  "lengths: lengths do not match coordinates: empty ring",
+		},
+		{
+			Data: &proto.Data{
+				Dimensions: 2,
+				DataType: &proto.Data_Feature_{Feature: &proto.Data_Feature{
+					Properties: []uint32{0},
+				}},
+			},
+			Expected: decode.ErrOddProperties,
+			Message:  "properties: odd number of property indexes: 1",
+		},
+		{
+			Data: &proto.Data{
+				Keys:       []string{"a"},
+				Dimensions: 2,
+				DataType: &proto.Data_FeatureCollection_{FeatureCollection: &proto.Data_FeatureCollection{
+					Features: []*proto.Data_Feature{
+						{},
+						{
+							Values:     []*proto.Data_Value{{}},
+							Properties: []uint32{1, 0},
+						},
+					},
+				}},
+			},
+			Expected: decode.ErrKeyOutOfRange,
+			Message:  "feature 1: properties: key index out of range: 1 of 1 at pair 0",
+		},
+		{
+			Data: &proto.Data{
+				Keys:       []string{"a"},
+				Dimensions: 2,
+				DataType: &proto.Data_Feature_{Feature: &proto.Data_Feature{
+					Properties: []uint32{0, 0},
+				}},
+			},
+			Expected: decode.ErrValueOutOfRange,
+			Message:  "properties: value index out of range: 0 of 0 at pair 0",
+		},
+		{
+			Data: &proto.Data{
+				Keys:       []string{"a"},
+				Dimensions: 2,
+				DataType: &proto.Data_Feature_{Feature: &proto.Data_Feature{
+					Values:     []*proto.Data_Value{{}},
+					Properties: []uint32{0, 0},
+				}},
+			},
+			Expected: decode.ErrEmptyValue,
+			Message:  "properties: value 0: value has no type",
+		},
+	}
+
+	for i, test := range testCases {
+		decoded, err := DecodeWithError(test.Data)
+		if decoded != nil {
+			t.Errorf("Case [%d]: Expected nil, got %+v", i, decoded)
+		}
+		if !errors.Is(err, test.Expected) {
+			t.Errorf("Case [%d]: Expected %s, got %v", i, test.Expected, err)
+		} else if err.Error() != test.Message {
+			t.Errorf("Case [%d]: Expected message %q, got %q", i, test.Message, err.Error())
+		}
+	}
+}
+
+func TestDecodeNoData(t *testing.T) {
+	if decoded := Decode(&proto.Data{}); !reflect.DeepEqual(struct{}{}, decoded) {
+		t.Errorf("Expected %+v, got %+v", struct{}{}, decoded)
+	}
+}
+
+func TestDecodeImplausibleDimension(t *testing.T) {
+	geometries := []*proto.Data_Geometry{
+		{Type: proto.Data_Geometry_LINESTRING},
+		{Type: proto.Data_Geometry_MULTIPOINT},
+		{Type: proto.Data_Geometry_LINESTRING, Coords: []int64{1, 2}},
+		{Type: proto.Data_Geometry_MULTIPOLYGON, Lengths: []uint32{1, 1, 0xFFFFFFFF}},
+	}
+
+	for i, geom := range geometries {
+		data := &proto.Data{
+			Dimensions: 0xFFFFFFF0,
+			DataType:   &proto.Data_Geometry_{Geometry: geom},
+		}
+		if _, err := DecodeWithError(data); !errors.Is(err, decode.ErrInvalidDimension) {
+			t.Errorf("Case [%d]: Expected %s, got %v", i, decode.ErrInvalidDimension, err)
+		}
+	}
+}
+
+func TestDecodeMultiPolygonMixedRings(t *testing.T) {
+	p := geojson.NewGeometry(
+		geometry.MultiPolygon([]geometry.Polygon{
+			geometry.Polygon([]geometry.Ring{
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{124.123, 234.456}),
+					geometry.Point([]float64{345.567, 456.678}),
+					geometry.Point([]float64{345.567, 234.456}),
+					geometry.Point([]float64{124.123, 234.456}),
+				}),
+			}),
+			geometry.Polygon([]geometry.Ring{
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{224.123, 334.456}),
+					geometry.Point([]float64{445.567, 556.678}),
+					geometry.Point([]float64{224.123, 334.456}),
+				}),
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{124.123, 234.456}),
+					geometry.Point([]float64{345.567, 456.678}),
+					geometry.Point([]float64{124.123, 234.456}),
+				}),
+			}),
+		}))
+	encoded := Encode(p)
+	decoded, err := DecodeWithError(encoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	if !reflect.DeepEqual(p, decoded) {
+		t.Errorf("Expected %+v, got %+v", p, decoded)
+	}
+}
+
+func TestDecodeGeometryCollection(t *testing.T) {
+	point := geometry.Point([]float64{124.123, 234.456})
+	polygon := geometry.Polygon([]geometry.Ring{
+		geometry.Ring([]geometry.Point{
+			geometry.Point([]float64{124.123, 234.456}),
+			geometry.Point([]float64{345.567, 456.678}),
+			geometry.Point([]float64{124.123, 234.456}),
+		}),
+	})
+	line := geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{224.123, 334.456}),
+		geometry.Point([]float64{445.567, 556.678}),
+	})
+
+	testCases := []geometry.Collection{
+		geometry.Collection{},
+		geometry.Collection{point, polygon},
+		geometry.Collection{point, geometry.Collection{line, polygon}, geometry.Collection{}},
+	}
+
+	for i, collection := range testCases {
+		p := geojson.NewGeometry(collection)
+		encoded := Encode(p)
+		decoded, err := DecodeWithError(encoded)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		if !reflect.DeepEqual(p, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, p, decoded)
+		}
+	}
+}
+
+func TestDecodeFeatureGeometryCollection(t *testing.T) {
+	p := geojson.NewFeature(geometry.Collection{
+		geometry.Point([]float64{124.123, 234.456}),
+		geometry.Collection{
+			geometry.MultiPoint([]geometry.Point{
+				geometry.Point([]float64{224.123, 334.456}),
+				geometry.Point([]float64{445.567, 556.678}),
+			}),
+		},
+	})
+	p.ID = "1234"
+	p.Properties["string"] = "string"
+	encoded := Encode(p)
+	decoded, err := DecodeWithError(encoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	if !reflect.DeepEqual(p, decoded) {
+		t.Errorf("Expected %+v, got %+v", p, decoded)
+	}
+}
+
+func TestDecodeHigherDimensions(t *testing.T) {
+	for _, dim := range []int{3, 4} {
+		point := func(coords ...float64) geometry.Point {
+			return geometry.Point(coords[:dim])
+		}
+		ring := geometry.Ring([]geometry.Point{
+			point(124.123, 234.456, 12.5, 1),
+			point(345.567, 456.678, 13.25, 2),
+			point(12.5, 456.678, -1.125, 3),
+			point(124.123, 234.456, 12.5, 1),
+		})
+		hole := geometry.Ring([]geometry.Point{
+			point(224.123, 334.456, 0, 0),
+			point(445.567, 556.678, 101.001, 0.5),
+			point(224.123, 334.456, 0, 0),
+		})
+		line := geometry.LineString([]geometry.Point{
+			point(124.123, 234.456, 8848.86, 1600000000),
+			point(-345.567, -456.678, -10994, 1600000001),
+			point(-12.001, 0, 0.1, 1600000002),
+		})
+
+		testCases := []geometry.Geometry{
+			point(124.123, 234.456, 8848.86, 1600000000),
+			geometry.MultiPoint([]geometry.Point{
+				point(124.123, 234.456, 8848.86, 1600000000),
+				point(-345.567, -456.678, -10994, 1600000001),
+			}),
+			line,
+			geometry.MultiLineString([]geometry.LineString{line, line}),
+			geometry.Polygon([]geometry.Ring{ring, hole}),
+			geometry.MultiPolygon([]geometry.Polygon{
+				geometry.Polygon([]geometry.Ring{ring}),
+				geometry.Polygon([]geometry.Ring{ring, hole}),
+			}),
+			geometry.Collection{point(1.5, 2.5, 3.5, 4.5), line},
+		}
+
+		for i, test := range testCases {
+			p := geojson.NewGeometry(test)
+			encoded := Encode(p)
+			if encoded.Dimensions != uint32(dim) {
+				t.Errorf("Case [%dD %d]: Expected dimension %d, got %d", dim, i, dim, encoded.Dimensions)
+			}
+
+			decoded, err := DecodeWithError(encoded)
+			if err != nil {
+				t.Fatalf("Case [%dD %d]: Got unexpected error %s!", dim, i, err)
+			}
+			if !reflect.DeepEqual(p, decoded) {
+				t.Errorf("Case [%dD %d]: Expected %+v, got %+v", dim, i, p, decoded)
+			}
+		}
+	}
+}
+
+func TestDecodeMixedDimensions(t *testing.T) {
+	p := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{124.123, 234.456, 10}),
+		geometry.Point([]float64{345.567, 456.678}),
+	}))
+	expected := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{124.123, 234.456, 10}),
+		geometry.Point([]float64{345.567, 456.678, 0}),
+	}))
+	decoded, err := DecodeWithError(Encode(p))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+}
+
+func TestDecodeCustomProperties(t *testing.T) {
+	point := geojson.NewGeometry(geometry.Point([]float64{124.123, 234.456}))
+	point.Custom = geojson.Properties{"crs": "local"}
+
+	child := geojson.NewGeometry(geometry.Point([]float64{1, 2}))
+	child.Custom = geojson.Properties{"title": "child"}
+	collectionGeo := &geojson.Geometry{
+		Type:       geojson.GeometryCollectionType,
+		Geometries: []*geojson.Geometry{child},
+		Custom:     geojson.Properties{"title": "parent"},
+	}
+
+	feature := geojson.NewFeature(geometry.Point([]float64{124.123, 234.456}))
+	feature.ID = "1234"
+	feature.Properties["title"] = "property"
+	feature.Custom = geojson.Properties{"title": "custom", "version": uint(2)}
+
+	collection := geojson.NewFeatureCollection()
+	collection.Append(feature)
+	collection.Custom = geojson.Properties{"name": "parcels", "count": float64(1.5)}
+
+	testCases := []interface{}{point, collectionGeo, feature, collection}
+	for i, test := range testCases {
+		decoded, err := DecodeWithError(Encode(test))
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		if !reflect.DeepEqual(test, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test, decoded)
+		}
+	}
+}
+
+func TestDecodeWithInt64Numbers(t *testing.T) {
+	p := geojson.NewFeature(geometry.Point([]float64{124.123, 234.456}))
+	p.Properties["int8"] = int8(5)
+	p.Properties["int64"] = int64(5)
+	p.Properties["uint"] = uint(5)
+	p.Properties["neg"] = int16(-5)
+	p.Properties["float"] = float32(1.5)
+
+	decoded, err := DecodeWithOptions(Encode(p), decode.WithInt64Numbers())
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	expected := geojson.Properties{
+		"int8":  int64(5),
+		"int64": int64(5),
+		"uint":  int64(5),
+		"neg":   int64(-5),
+		"float": float64(1.5),
+	}
+	if !reflect.DeepEqual(expected, decoded.(*geojson.Feature).Properties) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded.(*geojson.Feature).Properties)
+	}
+}
+
+func TestDecodeNestedProperties(t *testing.T) {
+	p := geojson.NewFeature(geometry.Point([]float64{124.123, 234.456}))
+	p.Properties["tags"] = []interface{}{"A", "B"}
+	p.Properties["nested"] = map[string]interface{}{
+		"list": []interface{}{float64(1), "two"},
+		"ok":   true,
+	}
+	p.Custom = geojson.Properties{
+		"title": "nested",
+	}
+	p.BBox = geojson.BBox{124.123, 234.456, 124.123, 234.456}
+
+	decoded, err := DecodeWithError(Encode(p))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	if !reflect.DeepEqual(p, decoded) {
+		t.Errorf("Expected %+v, got %+v", p, decoded)
+	}
+}
@@ -0,0 +1,208 @@
+package geobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	"github.com/cairnapp/go-geobuf/pkg/decode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+// Protobuf messages are limited to 2GB
+const maxMessageSize = 1<<31 - 1
+
+var ErrNotFeatures = errors.New("geobuf: stream holds a geometry, not features")
+
+// ErrSharedValues is returned by Decoder for collections written with
+// encode.WithSharedValues, whose values only follow the features.
+var ErrSharedValues = errors.New("geobuf: features reference shared collection values, which can't be streamed; use Unmarshal instead")
+
+// A Decoder reads features from a geobuf stream one at a time, without
+// holding the whole collection in memory.
+//
+// Keys, precision and dimensions are picked up as they are read, so they
+// must come before the features that use them. This is the case for
+// anything written by Marshal or Encoder, except for collections written
+// with encode.WithSharedValues: their values are stored after the features,
+// so Next fails with ErrSharedValues once a feature refers to them.
+type Decoder struct {
+	r      *wireReader
+	cfg    *decode.DecodingConfig
+	header *proto.Data
+
+	// end is the offset at which the current feature collection ends
+	inCollection bool
+	end          uint64
+	index        int
+	err          error
+}
+
+func NewDecoder(r io.Reader, opts ...decode.DecodingOption) *Decoder {
+	cfg := &decode.DecodingConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &Decoder{
+		r:      newWireReader(r),
+		cfg:    cfg,
+		header: &proto.Data{},
+	}
+}
+
+// Next returns the next feature in the stream, or io.EOF once there are no
+// more. A feature that can't be decoded is reported as a *decode.FeatureError,
+// after which Next can carry on with the following feature; any other error
+// is final.
+func (d *Decoder) Next() (*geojson.Feature, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	feature, err := d.next()
+	if _, ok := err.(*decode.FeatureError); err != nil && !ok {
+		d.err = err
+	}
+	return feature, err
+}
+
+func (d *Decoder) next() (*geojson.Feature, error) {
+	for {
+		if d.inCollection {
+			if d.r.offset >= d.end {
+				d.inCollection = false
+				continue
+			}
+
+			field, wireType, err := d.r.readTag()
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return nil, err
+			}
+			if d.r.offset > d.end {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			limit := d.end - d.r.offset
+			if field == collectionFeaturesField && wireType == protobuf.WireBytes {
+				buf, err := d.r.readBytes(limit)
+				if err != nil {
+					return nil, err
+				}
+				return d.decodeFeature(buf)
+			}
+			if err := d.r.skip(wireType, limit); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		field, wireType, err := d.r.readTag()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case field == dataKeysField && wireType == protobuf.WireBytes:
+			buf, err := d.r.readBytes(maxMessageSize)
+			if err != nil {
+				return nil, err
+			}
+			d.header.Keys = append(d.header.Keys, string(buf))
+		case field == dataDimensionsField && wireType == protobuf.WireVarint:
+			val, err := d.r.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			d.header.Dimensions = uint32(val)
+		case field == dataPrecisionField && wireType == protobuf.WireVarint:
+			val, err := d.r.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			d.header.Precision = uint32(val)
+		case field == dataPrecisionsField && wireType == protobuf.WireVarint:
+			val, err := d.r.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			d.header.DimensionPrecisions = append(d.header.DimensionPrecisions, uint32(val))
+		case field == dataPrecisionsField && wireType == protobuf.WireBytes:
+			// Packed, as proto3 writes repeated scalars
+			buf, err := d.r.readBytes(maxMessageSize)
+			if err != nil {
+				return nil, err
+			}
+			for len(buf) > 0 {
+				val, n := protobuf.DecodeVarint(buf)
+				if n == 0 {
+					return nil, errors.New("geobuf: malformed dimension precisions")
+				}
+				d.header.DimensionPrecisions = append(d.header.DimensionPrecisions, uint32(val))
+				buf = buf[n:]
+			}
+		case field == dataTransformField && wireType == protobuf.WireFixed64:
+			val, err := d.r.readFixed64()
+			if err != nil {
+				return nil, err
+			}
+			d.header.Transform = append(d.header.Transform, math.Float64frombits(val))
+		case field == dataTransformField && wireType == protobuf.WireBytes:
+			buf, err := d.r.readBytes(maxMessageSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(buf)%8 != 0 {
+				return nil, errors.New("geobuf: malformed transform")
+			}
+			for ; len(buf) > 0; buf = buf[8:] {
+				d.header.Transform = append(d.header.Transform, math.Float64frombits(binary.LittleEndian.Uint64(buf)))
+			}
+		case field == dataFeatureCollectionField && wireType == protobuf.WireBytes:
+			length, err := d.r.readLength(maxMessageSize)
+			if err != nil {
+				return nil, err
+			}
+			d.inCollection = true
+			d.end = d.r.offset + length
+		case field == dataFeatureField && wireType == protobuf.WireBytes:
+			buf, err := d.r.readBytes(maxMessageSize)
+			if err != nil {
+				return nil, err
+			}
+			return d.decodeFeature(buf)
+		case field == dataGeometryField:
+			return nil, ErrNotFeatures
+		default:
+			if err := d.r.skip(wireType, maxMessageSize); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (d *Decoder) decodeFeature(buf []byte) (*geojson.Feature, error) {
+	msg := &proto.Data_Feature{}
+	if err := protobuf.Unmarshal(buf, msg); err != nil {
+		return nil, err
+	}
+	// A feature with properties always carries its own values, unless it
+	// refers to the collection's
+	if len(msg.Values) == 0 && (len(msg.Properties) > 0 || len(msg.CustomProperties) > 0) {
+		return nil, ErrSharedValues
+	}
+
+	feature, err := decode.DecodeFeature(d.header, msg, d.header.Precision, d.header.Dimensions, d.cfg)
+	if err != nil {
+		err = &decode.FeatureError{Index: d.index, Err: err}
+	}
+	d.index++
+	return feature, err
+}
@@ -0,0 +1,226 @@
+package geobuf_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	. "github.com/cairnapp/go-geobuf"
+	"github.com/cairnapp/go-geobuf/pkg/decode"
+	"github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+func decodeAll(t *testing.T, buf []byte) []*geojson.Feature {
+	features := []*geojson.Feature{}
+	dec := NewDecoder(bytes.NewReader(buf))
+	for {
+		feature, err := dec.Next()
+		if err == io.EOF {
+			return features
+		}
+		if err != nil {
+			t.Fatalf("Got unexpected error %s!", err)
+		}
+		features = append(features, feature)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	collection := encoderFixture()
+	buf, err := Marshal(collection)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	features := decodeAll(t, buf)
+	if !reflect.DeepEqual(collection.Features, features) {
+		t.Errorf("Expected %+v, got %+v", collection.Features, features)
+	}
+}
+
+func TestDecoderStreamedInput(t *testing.T) {
+	testCases := [][]encode.EncodingOption{
+		{encode.WithPrecision(3)},
+		{encode.WithPrecision(3), encode.WithKeyStore(encode.NewKeyStoreWithKeys([]string{"string", "int", "bool"}))},
+	}
+
+	for i, opts := range testCases {
+		collection := encoderFixture()
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, opts...)
+		for _, feature := range collection.Features {
+			if err := enc.Encode(feature); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		features := decodeAll(t, buf.Bytes())
+		if !reflect.DeepEqual(collection.Features, features) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, collection.Features, features)
+		}
+	}
+}
+
+func TestDecoderDimensionPrecision(t *testing.T) {
+	feature := geojson.NewFeature(geometry.Point([]float64{174.123, -36.84, 12.5}))
+	buf := &bytes.Buffer{}
+	enc := NewEncoder(buf, encode.WithPrecision(3), encode.WithDimension(3), encode.WithDimensionPrecision(2, 1))
+	if err := enc.Encode(feature); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	features := decodeAll(t, buf.Bytes())
+	if !reflect.DeepEqual([]*geojson.Feature{feature}, features) {
+		t.Errorf("Expected %+v, got %+v", feature, features)
+	}
+}
+
+func TestDecoderAffine(t *testing.T) {
+	affine := geometry.Affine{Scale: [2]float64{0.5, -0.5}, Translate: [2]float64{100, 200}}
+	feature := geojson.NewFeature(geometry.LineString{{100.5, 199, 12.5}, {102, 197.5, 13}})
+	buf := &bytes.Buffer{}
+	enc := NewEncoder(buf, encode.WithPrecision(1), encode.WithDimension(3), encode.WithAffine(affine))
+	if err := enc.Encode(feature); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	features := decodeAll(t, buf.Bytes())
+	if !reflect.DeepEqual([]*geojson.Feature{feature}, features) {
+		t.Errorf("Expected %+v, got %+v", feature, features)
+	}
+}
+
+func TestDecoderSharedValues(t *testing.T) {
+	collection := encoderFixture()
+	buf, err := MarshalWithOptions(collection, encode.FromAnalysis(collection), encode.WithSharedValues())
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf))
+	if _, err := dec.Next(); !errors.Is(err, ErrSharedValues) {
+		t.Fatalf("Expected %s, got %v", ErrSharedValues, err)
+	}
+	if _, err := dec.Next(); !errors.Is(err, ErrSharedValues) {
+		t.Errorf("Expected %s to be final, got %v", ErrSharedValues, err)
+	}
+}
+
+func TestDecoderSingleFeature(t *testing.T) {
+	feature := encoderFixture().Features[0]
+	buf, err := Marshal(feature)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	features := decodeAll(t, buf)
+	if !reflect.DeepEqual([]*geojson.Feature{feature}, features) {
+		t.Errorf("Expected %+v, got %+v", feature, features)
+	}
+}
+
+func TestDecoderEmpty(t *testing.T) {
+	features := decodeAll(t, nil)
+	if len(features) != 0 {
+		t.Errorf("Expected no features, got %+v", features)
+	}
+}
+
+func TestDecoderGeometry(t *testing.T) {
+	buf, err := Marshal(geojson.NewGeometry(geometry.Point([]float64{1, 2})))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	_, err = NewDecoder(bytes.NewReader(buf)).Next()
+	if err != ErrNotFeatures {
+		t.Errorf("Expected %s, got %v", ErrNotFeatures, err)
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	buf, err := Marshal(encoderFixture())
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf[:len(buf)-3]))
+	if _, err := dec.Next(); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if _, err := dec.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+	if _, err := dec.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected the error to stick, got %v", err)
+	}
+}
+
+func TestDecoderTruncatedLength(t *testing.T) {
+	// A collection whose first feature claims a gigabyte, but holds 3 bytes
+	var buf []byte
+	buf = append(buf, protobuf.EncodeVarint(4<<3|protobuf.WireBytes)...)
+	buf = append(buf, protobuf.EncodeVarint(1<<31-1)...)
+	buf = append(buf, protobuf.EncodeVarint(1<<3|protobuf.WireBytes)...)
+	buf = append(buf, protobuf.EncodeVarint(1<<30)...)
+	buf = append(buf, 1, 2, 3)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := NewDecoder(bytes.NewReader(buf)).Next()
+	runtime.ReadMemStats(&after)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Expected to allocate less than 1MB, got %d bytes", allocated)
+	}
+}
+
+func TestDecoderInvalidFeature(t *testing.T) {
+	buf, err := protobuf.Marshal(&proto.Data{
+		Keys:       []string{"a"},
+		Dimensions: 2,
+		DataType: &proto.Data_FeatureCollection_{FeatureCollection: &proto.Data_FeatureCollection{
+			Features: []*proto.Data_Feature{
+				{Properties: []uint32{3, 0}, Values: []*proto.Data_Value{{}}},
+				{IdType: &proto.Data_Feature_IntId{IntId: 2}},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf))
+	_, err = dec.Next()
+	featureErr := &decode.FeatureError{}
+	if !errors.As(err, &featureErr) || featureErr.Index != 0 {
+		t.Fatalf("Expected a feature error for feature 0, got %v", err)
+	}
+
+	feature, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if feature.ID != int64(2) {
+		t.Errorf("Expected feature 2, got %+v", feature)
+	}
+}
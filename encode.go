@@ -1,6 +1,8 @@
 package geobuf
 
 import (
+	protobuf "github.com/golang/protobuf/proto"
+
 	"github.com/cairnapp/go-geobuf/pkg/encode"
 	"github.com/cairnapp/go-geobuf/pkg/geojson"
 	"github.com/cairnapp/go-geobuf/pkg/math"
@@ -25,11 +27,18 @@ func EncodeWithOptions(obj interface{}, opts ...encode.EncodingOption) (*proto.D
 		opt(cfg)
 	}
 
-	data := &proto.Data{
-		Keys:       cfg.Keys.Keys(),
-		Dimensions: uint32(cfg.Dimension),
-		Precision:  math.EncodePrecision(cfg.Precision),
+	// Sampled analysis leaves coordinates it didn't look at to overflow
+	// here, so try again at the precision they fit
+	for {
+		data, err := encodeData(obj, cfg)
+		if err == nil || !cfg.LowerPrecision(err) {
+			return data, err
+		}
 	}
+}
+
+func encodeData(obj interface{}, cfg *encode.EncodingConfig) (*proto.Data, error) {
+	data := newData(cfg)
 
 	switch t := obj.(type) {
 	case *geojson.FeatureCollection:
@@ -49,10 +58,60 @@ func EncodeWithOptions(obj interface{}, opts ...encode.EncodingOption) (*proto.D
 			Feature: feature,
 		}
 	case *geojson.Geometry:
+		geometry, err := encode.EncodeGeometry(t, cfg)
+		if err != nil {
+			return nil, err
+		}
 		data.DataType = &proto.Data_Geometry_{
-			Geometry: encode.EncodeGeometry(t, cfg),
+			Geometry: geometry,
 		}
 	}
 
 	return data, nil
 }
+
+// newData returns a message holding the header fields for cfg, without any
+// data.
+func newData(cfg *encode.EncodingConfig) *proto.Data {
+	data := &proto.Data{
+		Keys:       cfg.Keys.Keys(),
+		Dimensions: uint32(cfg.Dimension),
+		Precision:  math.EncodePrecision(cfg.Precision),
+	}
+	if cfg.Affine != nil {
+		data.Transform = []float64{
+			cfg.Affine.Scale[0], cfg.Affine.Scale[1],
+			cfg.Affine.Translate[0], cfg.Affine.Translate[1],
+		}
+	}
+
+	// Dimension precisions are only written when they differ, so that the
+	// output stays the same for everyone else
+	precisions := cfg.Precisions()
+	for _, e := range precisions {
+		if e != cfg.Precision {
+			data.DimensionPrecisions = make([]uint32, len(precisions))
+			for i, e := range precisions {
+				data.DimensionPrecisions[i] = math.EncodePrecision(e)
+			}
+			break
+		}
+	}
+	return data
+}
+
+// Marshal encodes obj and serializes it to geobuf bytes, inferring the
+// precision and keys the same way Encode does.
+func Marshal(obj interface{}) ([]byte, error) {
+	return MarshalWithOptions(obj, encode.FromAnalysis(obj))
+}
+
+// MarshalWithOptions is Marshal with explicit encoding options, mirroring
+// EncodeWithOptions.
+func MarshalWithOptions(obj interface{}, opts ...encode.EncodingOption) ([]byte, error) {
+	data, err := EncodeWithOptions(obj, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return protobuf.Marshal(data)
+}
@@ -0,0 +1,227 @@
+package geobuf
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	"github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+)
+
+var ErrEncoderClosed = errors.New("geobuf: encoder is closed")
+
+// An Encoder writes a geobuf feature collection to an output stream one
+// feature at a time, so the whole collection never has to be held in memory.
+//
+// Since nothing is analyzed up front, precision and dimension should be set
+// with encode.WithPrecision and encode.WithDimension. When a key store is
+// supplied with encode.WithKeyStore, the keys are written immediately and
+// each feature is streamed straight to the output; every property key must
+// then already be in the store. Otherwise keys are collected as features
+// arrive, and the features are spooled to a temporary file until Close writes
+// the keys followed by the spooled features.
+//
+// When streaming, the size of the collection is only known once it's
+// closed. If the output is an io.WriteSeeker, such as a file, Close goes back
+// and fills it in. Otherwise each feature is written in a collection of its
+// own, which protobuf parsers merge into one, but which decoders that don't,
+// like the reference JavaScript one, read as only the last feature.
+type Encoder struct {
+	w   io.Writer
+	cfg *encode.EncodingConfig
+
+	// streaming is set when the keys were supplied up front
+	streaming bool
+	started   bool
+	spool     *os.File
+	size      uint64
+	count     int
+	closed    bool
+	// seeker is set when streaming to an output Close can go back in, to
+	// write the size of the collection at sizeAt
+	seeker io.WriteSeeker
+	sizeAt int64
+}
+
+func NewEncoder(w io.Writer, opts ...encode.EncodingOption) *Encoder {
+	keys := encode.NewOrderedKeyStore()
+	cfg := &encode.EncodingConfig{
+		Dimension: 2,
+		Precision: 1,
+		Keys:      keys,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &Encoder{
+		w:         w,
+		cfg:       cfg,
+		streaming: cfg.Keys != keys,
+	}
+}
+
+// Encode writes a single feature to the collection.
+func (e *Encoder) Encode(feature *geojson.Feature) error {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+
+	if e.streaming && !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	if !e.streaming {
+		addSortedKeys(e.cfg.Keys, feature.Properties)
+		addSortedKeys(e.cfg.Keys, feature.Custom)
+		addSortedKeys(e.cfg.Keys, feature.GeometryCustom)
+		if len(feature.BBox) > 0 || len(feature.GeometryBBox) > 0 {
+			e.cfg.Keys.Add(geojson.BBoxMember)
+		}
+	}
+
+	encoded, err := encode.EncodeFeature(feature, e.cfg)
+	if err != nil {
+		return err
+	}
+	buf, err := protobuf.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+	entry := appendBytesField(nil, collectionFeaturesField, buf)
+
+	if e.streaming {
+		// Repeated occurrences of an embedded message are merged by protobuf
+		// parsers, so without a size to fill in later each feature can be
+		// wrapped in its own collection
+		if e.seeker == nil {
+			entry = appendBytesField(nil, dataFeatureCollectionField, entry)
+		}
+		n, err := e.w.Write(entry)
+		e.size += uint64(n)
+		if err != nil {
+			return err
+		}
+		e.count++
+		return nil
+	}
+
+	if e.spool == nil {
+		e.spool, err = ioutil.TempFile("", "geobuf")
+		if err != nil {
+			return err
+		}
+	}
+	n, err := e.spool.Write(entry)
+	e.size += uint64(n)
+	if err != nil {
+		return err
+	}
+	e.count++
+	return nil
+}
+
+// Close finishes the collection. It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+	e.closed = true
+
+	if e.streaming {
+		if !e.started {
+			if err := e.start(); err != nil {
+				return err
+			}
+		}
+		if e.seeker != nil {
+			return e.writeSize()
+		}
+		if e.count == 0 {
+			// Make sure an empty stream still decodes as a feature collection
+			_, err := e.w.Write(appendBytesField(nil, dataFeatureCollectionField, nil))
+			return err
+		}
+		return nil
+	}
+
+	defer e.removeSpool()
+	buf := e.header()
+	buf = appendTag(buf, dataFeatureCollectionField, protobuf.WireBytes)
+	buf = append(buf, protobuf.EncodeVarint(e.size)...)
+	if _, err := e.w.Write(buf); err != nil {
+		return err
+	}
+	if e.spool == nil {
+		return nil
+	}
+
+	if _, err := e.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(e.w, e.spool)
+	return err
+}
+
+// start writes the header of a streamed collection. Outputs that can seek
+// also get the start of the collection, with room for its size.
+func (e *Encoder) start() error {
+	buf := e.header()
+	if seeker, ok := e.w.(io.WriteSeeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			buf = appendTag(buf, dataFeatureCollectionField, protobuf.WireBytes)
+			e.seeker = seeker
+			e.sizeAt = offset + int64(len(buf))
+			buf = appendPaddedVarint(buf, 0)
+		}
+	}
+	e.started = true
+	_, err := e.w.Write(buf)
+	return err
+}
+
+// writeSize fills in the size of a streamed collection, leaving the output
+// at its end.
+func (e *Encoder) writeSize() error {
+	if _, err := e.seeker.Seek(e.sizeAt, io.SeekStart); err != nil {
+		return err
+	}
+	size := appendPaddedVarint(nil, e.size)
+	if _, err := e.seeker.Write(size); err != nil {
+		return err
+	}
+	_, err := e.seeker.Seek(e.sizeAt+int64(len(size))+int64(e.size), io.SeekStart)
+	return err
+}
+
+func (e *Encoder) header() []byte {
+	buf, _ := protobuf.Marshal(newData(e.cfg))
+	return buf
+}
+
+func (e *Encoder) removeSpool() {
+	if e.spool != nil {
+		e.spool.Close()
+		os.Remove(e.spool.Name())
+		e.spool = nil
+	}
+}
+
+// addSortedKeys adds the keys of props in lexical order, so that the key
+// table doesn't depend on map iteration order.
+func addSortedKeys(store encode.KeyStore, props map[string]interface{}) {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		store.Add(key)
+	}
+}
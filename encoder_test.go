@@ -0,0 +1,237 @@
+package geobuf_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	. "github.com/cairnapp/go-geobuf"
+	"github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func encoderFixture() *geojson.FeatureCollection {
+	p := geojson.NewFeature(geometry.Polygon([]geometry.Ring{
+		geometry.Ring([]geometry.Point{
+			geometry.Point([]float64{124.123, 234.456}),
+			geometry.Point([]float64{345.567, 456.678}),
+			geometry.Point([]float64{124.123, 234.456}),
+		}),
+	}))
+	p.ID = "1234"
+	p.Properties["string"] = "string"
+	p.Properties["int"] = uint(4)
+
+	p2 := geojson.NewFeature(geometry.Point([]float64{224.123, 334.456}))
+	p2.ID = int64(5679)
+	p2.Properties["bool"] = true
+	p2.Properties["int"] = uint(5)
+
+	collection := geojson.NewFeatureCollection()
+	collection.Append(p)
+	collection.Append(p2)
+	return collection
+}
+
+func TestEncoder(t *testing.T) {
+	testCases := [][]encode.EncodingOption{
+		{encode.WithPrecision(3)},
+		{encode.WithPrecision(3), encode.WithKeyStore(encode.NewKeyStoreWithKeys([]string{"string", "int", "bool"}))},
+	}
+
+	for i, opts := range testCases {
+		collection := encoderFixture()
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, opts...)
+		for _, feature := range collection.Features {
+			if err := enc.Encode(feature); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		decoded, err := Unmarshal(buf.Bytes())
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(collection, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, collection, decoded)
+		}
+	}
+}
+
+func TestEncoderDeterministic(t *testing.T) {
+	encodeFixture := func() []byte {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, encode.WithPrecision(3))
+		for _, feature := range deterministicFixture().Features {
+			if err := enc.Encode(feature); err != nil {
+				t.Fatalf("Got unexpected error %s!", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Got unexpected error %s!", err)
+		}
+		return buf.Bytes()
+	}
+
+	expected := encodeFixture()
+	for i := 0; i < 50; i++ {
+		if !bytes.Equal(expected, encodeFixture()) {
+			t.Fatalf("Case [%d]: Expected identical output for equal input", i)
+		}
+	}
+}
+
+// collectionFields counts the feature_collection fields of a Data message,
+// which decoders that don't merge them read as separate collections.
+func collectionFields(t *testing.T, data []byte) int {
+	count := 0
+	buf := protobuf.NewBuffer(data)
+	for len(buf.Unread()) > 0 {
+		tag, err := buf.DecodeVarint()
+		if err != nil {
+			t.Fatalf("Got unexpected error %s!", err)
+		}
+		switch tag & 7 {
+		case protobuf.WireVarint:
+			_, err = buf.DecodeVarint()
+		case protobuf.WireBytes:
+			_, err = buf.DecodeRawBytes(false)
+		case protobuf.WireFixed64:
+			_, err = buf.DecodeFixed64()
+		}
+		if err != nil {
+			t.Fatalf("Got unexpected error %s!", err)
+		}
+		if tag>>3 == 4 {
+			count++
+		}
+	}
+	return count
+}
+
+func TestEncoderStreamingCollections(t *testing.T) {
+	file, err := ioutil.TempFile("", "geobuf")
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	testCases := []struct {
+		Features int
+		Seekable bool
+		Expected int
+	}{
+		{2, true, 1},
+		{0, true, 1},
+		// Without seeking back, each feature gets a collection of its own
+		{2, false, 2},
+		{0, false, 1},
+	}
+
+	keys := []string{"string", "int", "bool"}
+	for i, test := range testCases {
+		collection := encoderFixture()
+		collection.Features = collection.Features[:test.Features]
+
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, encode.WithPrecision(3), encode.WithKeyStore(encode.NewKeyStoreWithKeys(keys)))
+		if test.Seekable {
+			// Something already in the file shouldn't get in the way
+			if err := file.Truncate(0); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+			if _, err := file.WriteAt(make([]byte, 1<<16), 0); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+			enc = NewEncoder(file, encode.WithPrecision(3), encode.WithKeyStore(encode.NewKeyStoreWithKeys(keys)))
+		}
+		for _, feature := range collection.Features {
+			if err := enc.Encode(feature); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		data := buf.Bytes()
+		if test.Seekable {
+			offset, err := file.Seek(0, io.SeekCurrent)
+			if err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+			data = make([]byte, offset)
+			if _, err := file.ReadAt(data, 0); err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+		}
+		if count := collectionFields(t, data); count != test.Expected {
+			t.Errorf("Case [%d]: Expected %d collections, got %d", i, test.Expected, count)
+		}
+		decoded, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(collection, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, collection, decoded)
+		}
+		if features := decodeAll(t, data); !reflect.DeepEqual(collection.Features, features) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, collection.Features, features)
+		}
+	}
+}
+
+func TestEncoderEmpty(t *testing.T) {
+	testCases := [][]encode.EncodingOption{
+		{},
+		{encode.WithKeyStore(encode.NewKeyStore())},
+	}
+
+	for i, opts := range testCases {
+		buf := &bytes.Buffer{}
+		if err := NewEncoder(buf, opts...).Close(); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		decoded, err := Unmarshal(buf.Bytes())
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(geojson.NewFeatureCollection(), decoded) {
+			t.Errorf("Case [%d]: Expected an empty collection, got %+v", i, decoded)
+		}
+	}
+}
+
+func TestEncoderUnknownKey(t *testing.T) {
+	enc := NewEncoder(&bytes.Buffer{}, encode.WithKeyStore(encode.NewKeyStoreWithKeys([]string{"int"})))
+	feature := geojson.NewFeature(geometry.Point([]float64{1, 2}))
+	feature.Properties["missing"] = 1
+	if err := enc.Encode(feature); err == nil {
+		t.Errorf("Expected an error for a key missing from the key store")
+	}
+}
+
+func TestEncoderClosed(t *testing.T) {
+	enc := NewEncoder(&bytes.Buffer{})
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if err := enc.Encode(geojson.NewFeature(nil)); err != ErrEncoderClosed {
+		t.Errorf("Expected %s, got %v", ErrEncoderClosed, err)
+	}
+}
@@ -0,0 +1,728 @@
+package geobuf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	. "github.com/cairnapp/go-geobuf"
+	"github.com/cairnapp/go-geobuf/pkg/decode"
+	"github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+	"github.com/cairnapp/go-geobuf/pkg/validate"
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	polygon := geometry.Polygon([]geometry.Ring{
+		geometry.Ring([]geometry.Point{
+			geometry.Point([]float64{124.123, 234.456}),
+			geometry.Point([]float64{345.567, 456.678}),
+			geometry.Point([]float64{124.123, 234.456}),
+		}),
+		geometry.Ring([]geometry.Point{
+			geometry.Point([]float64{224.123, 334.456}),
+			geometry.Point([]float64{445.567, 556.678}),
+			geometry.Point([]float64{224.123, 334.456}),
+		}),
+	})
+
+	feature := geojson.NewFeature(polygon)
+	feature.ID = "1234"
+	feature.Properties["int"] = uint(4)
+	feature.Properties["float"] = float64(2.0)
+	feature.Properties["neg_int"] = -1
+	feature.Properties["string"] = "string"
+	feature.Properties["bool"] = true
+
+	intFeature := geojson.NewFeature(polygon)
+	intFeature.ID = int64(1)
+	intFeature.Properties["int"] = uint(4)
+
+	collection := geojson.NewFeatureCollection()
+	collection.Append(feature)
+	collection.Append(intFeature)
+
+	testCases := []interface{}{
+		geojson.NewGeometry(geometry.Point([]float64{124.123, 234.456})),
+		geojson.NewGeometry(geometry.MultiPoint([]geometry.Point{
+			geometry.Point([]float64{124.123, 234.456}),
+			geometry.Point([]float64{345.567, 456.678}),
+		})),
+		geojson.NewGeometry(geometry.LineString([]geometry.Point{
+			geometry.Point([]float64{124.123, 234.456}),
+			geometry.Point([]float64{345.567, 456.678}),
+		})),
+		geojson.NewGeometry(geometry.MultiLineString([]geometry.LineString{
+			geometry.LineString([]geometry.Point{
+				geometry.Point([]float64{124.123, 234.456}),
+				geometry.Point([]float64{345.567, 456.678}),
+			}),
+			geometry.LineString([]geometry.Point{
+				geometry.Point([]float64{224.123, 334.456}),
+				geometry.Point([]float64{445.567, 556.678}),
+			}),
+		})),
+		geojson.NewGeometry(polygon),
+		geojson.NewGeometry(geometry.MultiPolygon([]geometry.Polygon{polygon, polygon})),
+		feature,
+		intFeature,
+		collection,
+	}
+
+	for i, test := range testCases {
+		buf, err := Marshal(test)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		decoded, err := Unmarshal(buf)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		if !reflect.DeepEqual(test, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test, decoded)
+		}
+	}
+}
+
+func TestMarshalWithOptions(t *testing.T) {
+	p := geojson.NewGeometry(geometry.Point([]float64{124.123, 234.456}))
+	buf, err := MarshalWithOptions(p, encode.WithPrecision(1))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	decoded, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	expected := geojson.NewGeometry(geometry.Point([]float64{124.1, 234.5}))
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+}
+
+func deterministicFixture() *geojson.FeatureCollection {
+	collection := geojson.NewFeatureCollection()
+	for i := 0; i < 10; i++ {
+		feature := geojson.NewFeature(geometry.Point([]float64{float64(i) + 0.5, 1.25}))
+		feature.ID = int64(i)
+		for j := 0; j < 20; j++ {
+			feature.Properties[fmt.Sprintf("key%d", j)] = i * j
+		}
+		feature.Properties["nested"] = map[string]interface{}{"a": 1, "b": []interface{}{"c", "d"}}
+		feature.Custom = geojson.Properties{"custom": "value"}
+		collection.Append(feature)
+	}
+	return collection
+}
+
+func TestMarshalDeterministic(t *testing.T) {
+	expected, err := Marshal(deterministicFixture())
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		buf, err := Marshal(deterministicFixture())
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !bytes.Equal(expected, buf) {
+			t.Fatalf("Case [%d]: Expected identical output for equal input", i)
+		}
+	}
+}
+
+func TestEncodeWithoutID(t *testing.T) {
+	feature := geojson.NewFeature(geometry.Point([]float64{1, 2}))
+
+	encoded, err := EncodeWithOptions(feature, encode.FromAnalysis(feature))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if id := encoded.GetFeature().IdType; id != nil {
+		t.Errorf("Expected no ID, got %v", id)
+	}
+
+	decoded, err := DecodeWithError(encoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if id := decoded.(*geojson.Feature).ID; id != nil {
+		t.Errorf("Expected no ID, got %#v", id)
+	}
+}
+
+func TestEncodeDeduplicatesValues(t *testing.T) {
+	feature := geojson.NewFeature(geometry.Point([]float64{1, 2}))
+	feature.Properties["a"] = "active"
+	feature.Properties["b"] = "active"
+	feature.Properties["c"] = uint(1)
+	feature.Properties["d"] = "1"
+	feature.Custom = geojson.Properties{"e": "active"}
+
+	encoded, err := EncodeWithOptions(feature, encode.FromAnalysis(feature))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if values := encoded.GetFeature().Values; len(values) != 3 {
+		t.Errorf("Expected 3 unique values, got %d", len(values))
+	}
+
+	decoded, err := DecodeWithError(encoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(feature, decoded) {
+		t.Errorf("Expected %+v, got %+v", feature, decoded)
+	}
+}
+
+func TestEncodeKeepsNegativeZero(t *testing.T) {
+	feature := geojson.NewFeature(geometry.Point([]float64{1, 2}))
+	feature.Properties["zero"] = 0.5 - 0.5
+	feature.Properties["negative"] = math.Copysign(0, -1)
+
+	encoded, err := EncodeWithOptions(feature, encode.FromAnalysis(feature))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if values := encoded.GetFeature().Values; len(values) != 2 {
+		t.Errorf("Expected 2 unique values, got %d", len(values))
+	}
+
+	decoded, err := DecodeWithError(encoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	negative, ok := decoded.(*geojson.Feature).Properties["negative"].(float64)
+	if !ok || !math.Signbit(negative) {
+		t.Errorf("Expected -0, got %v", decoded.(*geojson.Feature).Properties["negative"])
+	}
+}
+
+func TestEncodeSharedValues(t *testing.T) {
+	collection := sizeFixture(100)
+	collection.Custom = geojson.Properties{"status": "active"}
+
+	encoded, err := EncodeWithOptions(collection, encode.FromAnalysis(collection), encode.WithSharedValues())
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	for i, feature := range encoded.GetFeatureCollection().Features {
+		if len(feature.Values) > 0 {
+			t.Fatalf("Case [%d]: Expected no feature values, got %d", i, len(feature.Values))
+		}
+	}
+
+	decoded, err := DecodeWithError(encoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(collection, decoded) {
+		t.Errorf("Expected %+v, got %+v", collection, decoded)
+	}
+}
+
+// sizeFixture resembles a typical points of interest export, where most
+// properties come from a handful of values.
+func sizeFixture(n int) *geojson.FeatureCollection {
+	statuses := []string{"active", "inactive", "pending"}
+	categories := []string{"restaurant", "cafe", "bar", "hotel", "museum", "park"}
+	collection := geojson.NewFeatureCollection()
+	for i := 0; i < n; i++ {
+		feature := geojson.NewFeature(geometry.Point([]float64{float64(i%360-180) + 0.125, float64(i%180-90) + 0.0625}))
+		feature.ID = int64(i)
+		feature.Properties["status"] = statuses[i%len(statuses)]
+		feature.Properties["category"] = categories[i%len(categories)]
+		feature.Properties["country"] = "NZ"
+		feature.Properties["billing_country"] = "NZ"
+		feature.Properties["verified"] = i%2 == 0
+		feature.Properties["rating"] = uint(i % 5)
+		feature.Properties["name"] = fmt.Sprintf("Place %d", i)
+		collection.Append(feature)
+	}
+	return collection
+}
+
+func BenchmarkMarshalSize(b *testing.B) {
+	collection := sizeFixture(10000)
+	testCases := []struct {
+		name      string
+		opts      []encode.EncodingOption
+		duplicate bool
+	}{
+		{"Duplicated", nil, true},
+		{"PerFeature", nil, false},
+		{"Shared", []encode.EncodingOption{encode.WithSharedValues()}, false},
+	}
+
+	for _, test := range testCases {
+		b.Run(test.name, func(b *testing.B) {
+			opts := append([]encode.EncodingOption{encode.FromAnalysis(collection)}, test.opts...)
+			var size int
+			for i := 0; i < b.N; i++ {
+				encoded, err := EncodeWithOptions(collection, opts...)
+				if err != nil {
+					b.Fatalf("Got unexpected error %s!", err)
+				}
+				if test.duplicate {
+					duplicateValues(encoded)
+				}
+				buf, err := protobuf.Marshal(encoded)
+				if err != nil {
+					b.Fatalf("Got unexpected error %s!", err)
+				}
+				size = len(buf)
+			}
+			b.ReportMetric(float64(size), "bytes")
+		})
+	}
+}
+
+// duplicateValues undoes the deduplication of values, giving each property of
+// each feature a value of its own like features were encoded before.
+func duplicateValues(data *proto.Data) {
+	for _, feature := range data.GetFeatureCollection().GetFeatures() {
+		var values []*proto.Data_Value
+		for _, properties := range [][]uint32{feature.Properties, feature.CustomProperties} {
+			for i := 1; i < len(properties); i += 2 {
+				values = append(values, feature.Values[properties[i]])
+				properties[i] = uint32(len(values) - 1)
+			}
+		}
+		feature.Values = values
+	}
+}
+
+func TestEncodeMaxPrecision(t *testing.T) {
+	line := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{174.123456789, -36.84}),
+		geometry.Point([]float64{174.5, -36.123}),
+	}))
+
+	testCases := [][]encode.EncodingOption{
+		{encode.WithMaxPrecision(4), encode.FromAnalysis(line)},
+		{encode.FromAnalysis(line), encode.WithMaxPrecision(4)},
+	}
+
+	expected := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{174.1235, -36.84}),
+		geometry.Point([]float64{174.5, -36.123}),
+	}))
+	for i, opts := range testCases {
+		encoded, err := EncodeWithOptions(line, opts...)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if encoded.Precision != 4 {
+			t.Errorf("Case [%d]: Expected precision 4, got %d", i, encoded.Precision)
+		}
+
+		decoded, err := DecodeWithError(encoded)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(expected, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, expected, decoded)
+		}
+	}
+}
+
+func TestEncodeDimensionPrecision(t *testing.T) {
+	line := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{174.123456, -36.84, 12.123456789}),
+		geometry.Point([]float64{174.5, -36.123, 15.25}),
+	}))
+
+	testCases := [][]encode.EncodingOption{
+		{encode.WithDimensionPrecision(2, 1), encode.FromAnalysis(line)},
+		{encode.FromAnalysis(line), encode.WithDimensionPrecision(2, 1)},
+	}
+
+	expected := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{174.123456, -36.84, 12.1}),
+		geometry.Point([]float64{174.5, -36.123, 15.3}),
+	}))
+	for i, opts := range testCases {
+		buf, err := MarshalWithOptions(line, opts...)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		msg := &proto.Data{}
+		if err := protobuf.Unmarshal(buf, msg); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if msg.Precision != 6 || !reflect.DeepEqual([]uint32{6, 6, 1}, msg.DimensionPrecisions) {
+			t.Errorf("Case [%d]: Expected precision 6 and [6 6 1], got %d and %v", i, msg.Precision, msg.DimensionPrecisions)
+		}
+
+		decoded, err := Unmarshal(buf)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(expected, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, expected, decoded)
+		}
+	}
+}
+
+func TestMarshalCoordinateOverflow(t *testing.T) {
+	point := geojson.NewGeometry(geometry.Point([]float64{4.7e12, 0.123456789}))
+
+	_, err := MarshalWithOptions(point, encode.WithPrecision(9))
+	if !errors.Is(err, encode.ErrCoordinateOverflow) {
+		t.Errorf("Expected an overflow error, got %v", err)
+	}
+
+	// Analysis lowers the precision to what fits instead
+	buf, err := Marshal(point)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	decoded, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	expected := geojson.NewGeometry(geometry.Point([]float64{4.7e12, 0.12346}))
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+}
+
+func TestMarshalSampledCoordinateOverflow(t *testing.T) {
+	points := make(geometry.MultiPoint, 100)
+	for i := range points {
+		points[i] = geometry.Point([]float64{float64(i) + 0.123456789, 0})
+	}
+	// Sampling every tenth point skips this one
+	points[5] = geometry.Point([]float64{4.7e12, 0})
+	multiPoint := geojson.NewGeometry(points)
+
+	buf, err := MarshalWithOptions(multiPoint, encode.FromSampledAnalysis(multiPoint, 10))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	data := &proto.Data{}
+	if err := protobuf.Unmarshal(buf, data); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if data.Precision != 5 {
+		t.Errorf("Expected precision 5, got %d", data.Precision)
+	}
+}
+
+func TestMarshalBBox(t *testing.T) {
+	collection := encoderFixture()
+	collection.Custom = geojson.Properties{"status": "active"}
+	collection.ComputeBBox()
+
+	buf, err := Marshal(collection)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	decoded, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(collection, decoded) {
+		t.Errorf("Expected %+v, got %+v", collection, decoded)
+	}
+
+	geom := geojson.NewGeometry(geometry.Collection{geometry.Point([]float64{1, 2})})
+	geom.ComputeBBox()
+	buf, err = Marshal(geom)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	decodedGeom, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(geom, decodedGeom) {
+		t.Errorf("Expected %+v, got %+v", geom, decodedGeom)
+	}
+}
+
+func TestMarshalGeometryMembers(t *testing.T) {
+	source := `{"type":"Feature","geometry":{"type":"Point","bbox":[0,0,3,3],"coordinates":[1,2],"crs":"local"},"properties":{"name":"a"}}`
+	feature := &geojson.Feature{}
+	if err := json.Unmarshal([]byte(source), feature); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	buf, err := Marshal(feature)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	decoded, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(feature, decoded) {
+		t.Errorf("Expected %+v, got %+v", feature, decoded)
+	}
+	output, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if string(output) != source {
+		t.Errorf("Expected %s, got %s", source, output)
+	}
+
+	// The Encoder picks up the geometry's keys too
+	collection := geojson.NewFeatureCollection()
+	collection.Append(feature)
+	encoded := &bytes.Buffer{}
+	enc := NewEncoder(encoded)
+	if err := enc.Encode(feature); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	decoded, err = Unmarshal(encoded.Bytes())
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(collection, decoded) {
+		t.Errorf("Expected %+v, got %+v", collection, decoded)
+	}
+}
+
+func TestMarshalTransform(t *testing.T) {
+	line := geometry.LineString{{13.4, 52.5}, {13.41, 52.52}}
+	buf, err := MarshalWithOptions(
+		geojson.NewGeometry(line),
+		encode.WithTransform(geometry.WGS84ToWebMercator),
+		encode.FromAnalysis(geojson.NewGeometry(line)),
+		encode.WithMaxPrecision(3),
+	)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	projected, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	expected := line.Transform(geometry.WGS84ToWebMercator)
+	if got := projected.(*geojson.Geometry).Coordinates.(geometry.LineString); !got.EqualWithin(expected, 1e-3) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	decoded, err := UnmarshalWithOptions(buf, decode.WithTransform(geometry.WebMercatorToWGS84))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if got := decoded.(*geojson.Geometry).Coordinates.(geometry.LineString); !got.EqualWithin(line, 1e-7) {
+		t.Errorf("Expected %v, got %v", line, got)
+	}
+}
+
+func TestMarshalTransformBBox(t *testing.T) {
+	flip := func(p geometry.Point) geometry.Point {
+		return geometry.Point{-p[0], p[1] * 2}
+	}
+	unflip := func(p geometry.Point) geometry.Point {
+		return geometry.Point{-p[0], p[1] / 2}
+	}
+	original := geojson.BBox{1, 2, 3, 4}
+	line := geometry.LineString{{1, 2}, {3, 4}}
+	geom := geojson.NewGeometry(line)
+	geom.ComputeBBox()
+	feature := geojson.NewFeature(line)
+	feature.ComputeBBox()
+	collection := geojson.NewFeatureCollection()
+	collection.Append(feature)
+	collection.BBox = original
+
+	bbox := func(obj interface{}) geojson.BBox {
+		switch t := obj.(type) {
+		case *geojson.Geometry:
+			return t.BBox
+		case *geojson.FeatureCollection:
+			return t.BBox
+		}
+		return nil
+	}
+	expected := geojson.BBox{-3, 4, -1, 8}
+	for i, obj := range []interface{}{geom, collection} {
+		buf, err := MarshalWithOptions(obj, encode.WithTransform(flip), encode.FromAnalysis(obj))
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		decoded, err := Unmarshal(buf)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if got := bbox(decoded); !reflect.DeepEqual(expected, got) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, expected, got)
+		}
+		if c, ok := decoded.(*geojson.FeatureCollection); ok && !reflect.DeepEqual(expected, c.Features[0].BBox) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, expected, c.Features[0].BBox)
+		}
+
+		// Decoding transforms them back
+		restored, err := UnmarshalWithOptions(buf, decode.WithTransform(unflip))
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if got := bbox(restored); !reflect.DeepEqual(original, got) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, original, got)
+		}
+		if c, ok := restored.(*geojson.FeatureCollection); ok && !reflect.DeepEqual(original, c.Features[0].BBox) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, original, c.Features[0].BBox)
+		}
+	}
+}
+
+func TestMarshalTile(t *testing.T) {
+	// A square around Berlin, which lies in tile 10/550/335
+	square := geojson.NewGeometry(geometry.Polygon{{
+		{13.38, 52.51}, {13.42, 52.51}, {13.42, 52.53}, {13.38, 52.53}, {13.38, 52.51},
+	}})
+	data, err := EncodeWithOptions(
+		square,
+		encode.WithTransform(geometry.WGS84ToWebMercator),
+		encode.FromAnalysis(square),
+		encode.WithTile(10, 550, 335, 4096),
+	)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	for i, coord := range data.GetGeometry().Coords {
+		if coord < -4096 || coord > 4096 {
+			t.Errorf("Case [%d]: Expected a coordinate within the tile, got %d", i, coord)
+		}
+	}
+
+	decoded, err := DecodeWithOptions(data, decode.WithTransform(geometry.WebMercatorToWGS84))
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	// Rounding to the grid moves points by up to half a unit, which at zoom 10
+	// is less than 5e-5 degrees
+	expected := square.Coordinates.(geometry.Polygon)
+	if got := decoded.(*geojson.Geometry).Coordinates.(geometry.Polygon); !got.EqualWithin(expected, 5e-5) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	data.Transform = data.Transform[:3]
+	if _, err := DecodeWithError(data); !errors.Is(err, decode.ErrInvalidTransform) {
+		t.Errorf("Expected %v, got %v", decode.ErrInvalidTransform, err)
+	}
+}
+
+func TestMarshalRings(t *testing.T) {
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Expected geometry.Geometry
+		Err      error
+	}{
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}},
+			Expected: geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}},
+		},
+		{
+			Geometry: geometry.Polygon{{{1, 1}}},
+			Expected: geometry.Polygon{{{1, 1}, {1, 1}}},
+		},
+		{
+			Geometry: geometry.Polygon{{{1, 1}, {1, 1}}},
+			Expected: geometry.Polygon{{{1, 1}, {1, 1}}},
+		},
+		// Decoders reject empty rings, so they aren't written at all
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, {}},
+			Err:      encode.ErrEmptyRing,
+		},
+		{
+			Geometry: geometry.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{}}},
+			Err:      encode.ErrEmptyRing,
+		},
+	}
+
+	for i, test := range testCases {
+		buf, err := Marshal(geojson.NewGeometry(test.Geometry))
+		if test.Err != nil {
+			var coordErr *encode.CoordinateError
+			if !errors.As(err, &coordErr) || !errors.Is(err, test.Err) {
+				t.Errorf("Case [%d]: Expected %v, got %v", i, test.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		decoded, err := Unmarshal(buf)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if expected := geojson.NewGeometry(test.Expected); !reflect.DeepEqual(expected, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, expected, decoded)
+		}
+	}
+}
+
+func TestMarshalValidation(t *testing.T) {
+	collection := geojson.NewFeatureCollection()
+	collection.Append(geojson.NewFeature(geometry.Point{1, 2}))
+	collection.Append(geojson.NewFeature(geometry.LineString{{1, 2}, {3, 4}}))
+	collection.Append(geojson.NewFeature(geometry.LineString{{1, 2}}))
+
+	_, err := MarshalWithOptions(collection, encode.FromAnalysis(collection), encode.WithValidation())
+	var featureErr *encode.FeatureError
+	if !errors.As(err, &featureErr) || featureErr.Index != 2 || !errors.Is(err, validate.ErrTooFewPoints) {
+		t.Errorf("Expected too few points in feature 2, got %v", err)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	_, err := Unmarshal([]byte{0xff, 0xff, 0xff})
+	if err == nil {
+		t.Errorf("Expected an error for malformed input")
+	}
+}
+
+func TestGeoJSONRoundTrip(t *testing.T) {
+	source := `{"type":"FeatureCollection","features":[` +
+		`{"id":1,"type":"Feature","geometry":{"type":"Point","coordinates":[124.123,234.456]},"properties":{"name":"a","size":1.5}},` +
+		`{"id":"two","type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[1,2],[3,4.5]]}]},"properties":{"ok":true}},` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"missing":null}}` +
+		`]}`
+
+	collection := &geojson.FeatureCollection{}
+	if err := json.Unmarshal([]byte(source), collection); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	buf, err := Marshal(collection)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	decoded, err := Unmarshal(buf)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	output, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if string(output) != source {
+		t.Errorf("Expected %s, got %s", source, output)
+	}
+}
@@ -0,0 +1,42 @@
+package decode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+)
+
+// extractBBox moves the bounding box out of the decoded custom properties,
+// returning nil for custom if nothing else is left. The bounding box is
+// transformed like the coordinates it bounds.
+func extractBBox(custom map[string]interface{}, opts *DecodingConfig) (geojson.BBox, map[string]interface{}, error) {
+	val, ok := custom[geojson.BBoxMember]
+	if !ok {
+		return nil, custom, nil
+	}
+	delete(custom, geojson.BBoxMember)
+	if len(custom) == 0 {
+		custom = nil
+	}
+
+	var bbox geojson.BBox
+	switch typed := val.(type) {
+	case json.RawMessage:
+		if err := json.Unmarshal(typed, &bbox); err != nil {
+			return nil, nil, fieldError(geojson.BBoxMember, err)
+		}
+	case []interface{}:
+		bbox = make(geojson.BBox, len(typed))
+		for i, coord := range typed {
+			f, ok := coord.(float64)
+			if !ok {
+				return nil, nil, fieldError(geojson.BBoxMember, fmt.Errorf("expected numbers, got %T", coord))
+			}
+			bbox[i] = f
+		}
+	default:
+		return nil, nil, fieldError(geojson.BBoxMember, fmt.Errorf("expected an array, got %T", val))
+	}
+	return bbox.Transform(opts.Transform), custom, nil
+}
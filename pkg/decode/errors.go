@@ -0,0 +1,51 @@
+package decode
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownGeometryType = errors.New("unknown geometry type")
+	ErrInvalidDimension    = errors.New("invalid dimension")
+	ErrInvalidLengths      = errors.New("lengths do not match coordinates")
+	ErrOddProperties       = errors.New("odd number of property indexes")
+	ErrKeyOutOfRange       = errors.New("key index out of range")
+	ErrValueOutOfRange     = errors.New("value index out of range")
+	ErrEmptyValue          = errors.New("value has no type")
+	ErrInvalidTransform    = errors.New("invalid affine transform")
+)
+
+// FieldError reports which field of a geobuf message could not be decoded.
+// Nested fields wrap each other, so the message reads like a path,
+// e.g. "geometry: lengths: lengths do not match coordinates".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FeatureError reports which feature of a collection could not be decoded.
+type FeatureError struct {
+	Index int
+	Err   error
+}
+
+func (e *FeatureError) Error() string {
+	return fmt.Sprintf("feature %d: %s", e.Index, e.Err)
+}
+
+func (e *FeatureError) Unwrap() error {
+	return e.Err
+}
+
+func fieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
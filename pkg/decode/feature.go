@@ -2,49 +2,51 @@ package decode
 
 import (
 	"github.com/cairnapp/go-geobuf/pkg/geojson"
-	"github.com/cairnapp/go-geobuf/pkg/geometry"
 	"github.com/cairnapp/go-geobuf/proto"
 )
 
-func DecodeFeature(msg *proto.Data, feature *proto.Data_Feature, precision, dimension uint32) *geojson.Feature {
-	geo := feature.Geometry
-	decodedGeo := DecodeGeometry(geo, msg.Precision, msg.Dimensions)
+func DecodeFeature(msg *proto.Data, feature *proto.Data_Feature, precision, dimension uint32, opts *DecodingConfig) (*geojson.Feature, error) {
 	var geoFeature *geojson.Feature
-	switch decodedGeo.Type {
-	case geojson.GeometryCollectionType:
-		collection := make(geometry.Collection, len(decodedGeo.Geometries))
-		for i, child := range decodedGeo.Geometries {
-			collection[i] = child.Coordinates
+	if geo := feature.Geometry; geo != nil {
+		decodedGeo, err := DecodeGeometry(msg, geo, msg.Precision, msg.Dimensions, opts)
+		if err != nil {
+			return nil, fieldError("geometry", err)
 		}
-		geoFeature = geojson.NewFeature(collection)
-	default:
-		geoFeature = geojson.NewFeature(decodedGeo.Coordinates)
+		geoFeature = geojson.NewFeature(decodedGeo.Geometry())
+		geoFeature.GeometryBBox = decodedGeo.BBox
+		geoFeature.GeometryCustom = decodedGeo.Custom
+	} else {
+		geoFeature = geojson.NewFeature(nil)
 	}
 
-	for i := 0; i < len(feature.Properties); i = i + 2 {
-		keyIdx := feature.Properties[i]
-		valIdx := feature.Properties[i+1]
-		val := feature.Values[valIdx]
-		switch actualVal := val.ValueType.(type) {
-		case *proto.Data_Value_BoolValue:
-			geoFeature.Properties[msg.Keys[keyIdx]] = actualVal.BoolValue
-		case *proto.Data_Value_DoubleValue:
-			geoFeature.Properties[msg.Keys[keyIdx]] = actualVal.DoubleValue
-		case *proto.Data_Value_StringValue:
-			geoFeature.Properties[msg.Keys[keyIdx]] = actualVal.StringValue
-		case *proto.Data_Value_PosIntValue:
-			geoFeature.Properties[msg.Keys[keyIdx]] = uint(actualVal.PosIntValue)
-		case *proto.Data_Value_NegIntValue:
-			geoFeature.Properties[msg.Keys[keyIdx]] = int(actualVal.NegIntValue) * -1
-		case *proto.Data_Value_JsonValue:
-			geoFeature.Properties[msg.Keys[keyIdx]] = actualVal.JsonValue
+	// Features encoded with shared values reference the collection's values
+	values := feature.Values
+	if len(values) == 0 {
+		values = msg.GetFeatureCollection().GetValues()
+	}
+	props, err := decodeProperties(msg.Keys, values, feature.Properties, opts)
+	if err != nil {
+		return nil, fieldError("properties", err)
+	}
+	for key, val := range props {
+		geoFeature.Properties[key] = val
+	}
+	if len(feature.CustomProperties) > 0 {
+		custom, err := decodeProperties(msg.Keys, values, feature.CustomProperties, opts)
+		if err != nil {
+			return nil, fieldError("custom_properties", err)
+		}
+		geoFeature.BBox, geoFeature.Custom, err = extractBBox(custom, opts)
+		if err != nil {
+			return nil, fieldError("custom_properties", err)
 		}
 	}
+
 	switch id := feature.IdType.(type) {
 	case *proto.Data_Feature_Id:
 		geoFeature.ID = id.Id
 	case *proto.Data_Feature_IntId:
 		geoFeature.ID = id.IntId
 	}
-	return geoFeature
+	return geoFeature, nil
 }
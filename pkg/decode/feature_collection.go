@@ -0,0 +1,29 @@
+package decode
+
+import (
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+func DecodeFeatureCollection(msg *proto.Data, collection *proto.Data_FeatureCollection, precision, dimension uint32, opts *DecodingConfig) (*geojson.FeatureCollection, error) {
+	geoCollection := geojson.NewFeatureCollection()
+	for i, feature := range collection.Features {
+		decoded, err := DecodeFeature(msg, feature, precision, dimension, opts)
+		if err != nil {
+			return nil, &FeatureError{Index: i, Err: err}
+		}
+		geoCollection.Append(decoded)
+	}
+
+	if len(collection.CustomProperties) > 0 {
+		custom, err := decodeProperties(msg.Keys, collection.Values, collection.CustomProperties, opts)
+		if err != nil {
+			return nil, fieldError("custom_properties", err)
+		}
+		geoCollection.BBox, geoCollection.Custom, err = extractBBox(custom, opts)
+		if err != nil {
+			return nil, fieldError("custom_properties", err)
+		}
+	}
+	return geoCollection, nil
+}
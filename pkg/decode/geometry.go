@@ -1,104 +1,318 @@
 package decode
 
 import (
+	"fmt"
+
 	"github.com/cairnapp/go-geobuf/pkg/geojson"
 	"github.com/cairnapp/go-geobuf/pkg/geometry"
 	"github.com/cairnapp/go-geobuf/pkg/math"
 	"github.com/cairnapp/go-geobuf/proto"
 )
 
-func DecodeGeometry(geo *proto.Data_Geometry, precision, dimensions uint32) *geojson.Geometry {
+// maxDimension is the most dimensions a geometry may have. Dimensions come
+// straight from the message, so anything beyond it is taken for a malformed
+// one rather than something to size coordinates by.
+const maxDimension = 16
+
+func DecodeGeometry(msg *proto.Data, geo *proto.Data_Geometry, precision, dimensions uint32, opts *DecodingConfig) (*geojson.Geometry, error) {
+	// Matches the proto definition, where an unset dimension means 2D
+	if dimensions == 0 {
+		dimensions = 2
+	}
+	if dimensions > maxDimension {
+		return nil, fieldError("dimensions", fmt.Errorf("%w: %d, at most %d are supported", ErrInvalidDimension, dimensions, maxDimension))
+	}
+	precisions := dimensionPrecisions(msg, precision, dimensions, len(geo.Coords))
+
+	var (
+		decoded *geojson.Geometry
+		coords  geometry.Geometry
+		err     error
+	)
 	switch geo.Type {
 	case proto.Data_Geometry_POINT:
-		return geojson.NewGeometry(makePoint(geo.Coords, precision))
+		coords, err = makePoint(geo.Coords, precisions, dimensions)
 	case proto.Data_Geometry_MULTIPOINT:
-		return geojson.NewGeometry(makeMultiPoint(geo.Coords, precision, dimensions))
+		coords, err = makeMultiPoint(geo.Coords, precisions, dimensions)
 	case proto.Data_Geometry_LINESTRING:
-		return geojson.NewGeometry(makeLineString(geo.Coords, precision, dimensions))
+		coords, err = makeLineString(geo.Coords, precisions, dimensions)
 	case proto.Data_Geometry_MULTILINESTRING:
-		return geojson.NewGeometry(makeMultiLineString(geo.Lengths, geo.Coords, precision, dimensions))
+		coords, err = makeMultiLineString(geo.Lengths, geo.Coords, precisions, dimensions)
 	case proto.Data_Geometry_POLYGON:
-		return geojson.NewGeometry(makePolygon(geo.Lengths, geo.Coords, precision, dimensions))
+		coords, err = makePolygon(geo.Lengths, geo.Coords, precisions, dimensions)
 	case proto.Data_Geometry_MULTIPOLYGON:
-		return geojson.NewGeometry(makeMultiPolygon(geo.Lengths, geo.Coords, precision, dimensions))
+		coords, err = makeMultiPolygon(geo.Lengths, geo.Coords, precisions, dimensions)
+	case proto.Data_Geometry_GEOMETRYCOLLECTION:
+		decoded, err = makeCollection(msg, geo.Geometries, precision, dimensions, opts)
+	default:
+		return nil, fieldError("type", fmt.Errorf("%w: %d", ErrUnknownGeometryType, geo.Type))
 	}
-	return &geojson.Geometry{}
+	if err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		affine, err := affineOf(msg)
+		if err != nil {
+			return nil, err
+		}
+		if affine != nil {
+			coords = geometry.Transform(coords, affine.Apply)
+		}
+		if opts.Transform != nil {
+			coords = geometry.Transform(coords, opts.Transform)
+		}
+		decoded = geojson.NewGeometry(coords)
+	}
+
+	if len(geo.CustomProperties) > 0 {
+		custom, err := decodeProperties(msg.Keys, geo.Values, geo.CustomProperties, opts)
+		if err != nil {
+			return nil, fieldError("custom_properties", err)
+		}
+		decoded.BBox, decoded.Custom, err = extractBBox(custom, opts)
+		if err != nil {
+			return nil, fieldError("custom_properties", err)
+		}
+	}
+	return decoded, nil
 }
 
-func makePoint(inCords []int64, precision uint32) geometry.Point {
-	return geometry.Point(makeCoords(inCords, precision))
+// affineOf returns the affine transform the coordinates were quantized
+// with, or nil if they weren't.
+func affineOf(msg *proto.Data) (*geometry.Affine, error) {
+	t := msg.GetTransform()
+	switch len(t) {
+	case 0:
+		return nil, nil
+	case 4:
+		return &geometry.Affine{Scale: [2]float64{t[0], t[1]}, Translate: [2]float64{t[2], t[3]}}, nil
+	}
+	return nil, fieldError("transform", fmt.Errorf("%w: %d values, expected 4", ErrInvalidTransform, len(t)))
 }
 
-func makeMultiPoint(inCords []int64, precision uint32, dimension uint32) geometry.MultiPoint {
-	return geometry.MultiPoint(makeLine(inCords, precision, dimension, false))
+func makeCollection(msg *proto.Data, geometries []*proto.Data_Geometry, precision uint32, dimension uint32, opts *DecodingConfig) (*geojson.Geometry, error) {
+	children := make([]*geojson.Geometry, len(geometries))
+	for i, child := range geometries {
+		decoded, err := DecodeGeometry(msg, child, precision, dimension, opts)
+		if err != nil {
+			return nil, fieldError(fmt.Sprintf("geometries[%d]", i), err)
+		}
+		children[i] = decoded
+	}
+	return &geojson.Geometry{
+		Type:       geojson.GeometryCollectionType,
+		Geometries: children,
+	}, nil
 }
 
-func makeMultiPolygon(lengths []uint32, inCords []int64, precision uint32, dimension uint32) geometry.MultiPolygon {
+func makePoint(inCords []int64, precisions []uint32, dimension uint32) (geometry.Point, error) {
+	if dimension < 2 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidDimension, dimension)
+	}
+	if len(inCords) != int(dimension) {
+		return nil, fieldError("coords", fmt.Errorf("%w: point has %d coordinates, expected %d", ErrInvalidLengths, len(inCords), dimension))
+	}
+	return geometry.Point(makeCoords(inCords, precisions)), nil
+}
+
+func makeMultiPoint(inCords []int64, precisions []uint32, dimension uint32) (geometry.MultiPoint, error) {
+	points, err := makeLine(inCords, precisions, dimension, false)
+	return geometry.MultiPoint(points), err
+}
+
+func makeMultiPolygon(lengths []uint32, inCords []int64, precisions []uint32, dimension uint32) (geometry.MultiPolygon, error) {
+	// A single polygon with a single ring may omit its lengths entirely
+	if len(lengths) == 0 {
+		ring, err := makeRing(inCords, precisions, dimension)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.MultiPolygon{geometry.Polygon{ring}}, nil
+	}
+
+	// Every polygon needs at least its ring count, so a count beyond the
+	// lengths left is malformed rather than something to allocate for
 	polyCount := int(lengths[0])
-	polygons := make([]geometry.Polygon, polyCount)
 	lengths = lengths[1:]
+	if polyCount > len(lengths) {
+		return nil, fieldError("lengths", fmt.Errorf("%w: %d polygons, only %d lengths left", ErrInvalidLengths, polyCount, len(lengths)))
+	}
+	polygons := make([]geometry.Polygon, polyCount)
 	for i := 0; i < polyCount; i += 1 {
-		ringCount := lengths[0]
-		polygons[i] = makePolygon(lengths[1:ringCount+1], inCords, precision, dimension)
-		skip := 0
-		for i := 0; i < int(ringCount); i += 1 {
-			skip += int(lengths[i]) * int(dimension)
+		if len(lengths) == 0 {
+			return nil, fieldError("lengths", fmt.Errorf("%w: missing ring count for polygon %d", ErrInvalidLengths, i))
+		}
+		ringCount := int(lengths[0])
+		if ringCount > len(lengths)-1 {
+			return nil, fieldError("lengths", fmt.Errorf("%w: polygon %d has %d rings, only %d lengths left", ErrInvalidLengths, i, ringCount, len(lengths)-1))
+		}
+		ringLengths := lengths[1 : ringCount+1]
+		skip, ok := countCoords(ringLengths, dimension, len(inCords))
+		if !ok {
+			return nil, fieldError("lengths", fmt.Errorf("%w: polygon %d needs more than the %d coordinates left", ErrInvalidLengths, i, len(inCords)))
+		}
+
+		polygon, err := makePolygon(ringLengths, inCords[:skip], precisions, dimension)
+		if err != nil {
+			return nil, err
 		}
+		polygons[i] = polygon
 
-		lengths = lengths[ringCount:]
+		lengths = lengths[ringCount+1:]
 		inCords = inCords[skip:]
 	}
-	return geometry.MultiPolygon(polygons)
+	if len(lengths) != 0 || len(inCords) != 0 {
+		return nil, fieldError("lengths", fmt.Errorf("%w: %d lengths and %d coordinates left over", ErrInvalidLengths, len(lengths), len(inCords)))
+	}
+	return geometry.MultiPolygon(polygons), nil
 }
 
-func makePolygon(lengths []uint32, inCords []int64, precision uint32, dimension uint32) geometry.Polygon {
+func makePolygon(lengths []uint32, inCords []int64, precisions []uint32, dimension uint32) (geometry.Polygon, error) {
+	// A polygon with a single ring may omit its lengths entirely
+	if len(lengths) == 0 && len(inCords) > 0 {
+		ring, err := makeRing(inCords, precisions, dimension)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.Polygon{ring}, nil
+	}
+	if err := checkLengths(lengths, inCords, dimension); err != nil {
+		return nil, err
+	}
+
 	lines := make([]geometry.Ring, len(lengths))
 	for i, length := range lengths {
-		l := int(length * dimension)
-		lines[i] = makeRing(inCords[:l], precision, dimension)
+		l := int(length) * int(dimension)
+		ring, err := makeRing(inCords[:l], precisions, dimension)
+		if err != nil {
+			return nil, err
+		}
+		lines[i] = ring
 		inCords = inCords[l:]
 	}
 	poly := geometry.Polygon(lines)
-	return poly
+	return poly, nil
 }
 
-func makeMultiLineString(lengths []uint32, inCords []int64, precision uint32, dimension uint32) geometry.MultiLineString {
+func makeMultiLineString(lengths []uint32, inCords []int64, precisions []uint32, dimension uint32) (geometry.MultiLineString, error) {
+	// A single line may omit its lengths entirely
+	if len(lengths) == 0 && len(inCords) > 0 {
+		line, err := makeLineString(inCords, precisions, dimension)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.MultiLineString{line}, nil
+	}
+	if err := checkLengths(lengths, inCords, dimension); err != nil {
+		return nil, err
+	}
+
 	lines := make([]geometry.LineString, len(lengths))
 	for i, length := range lengths {
-		l := int(length * dimension)
-		lines[i] = makeLineString(inCords[:l], precision, dimension)
+		l := int(length) * int(dimension)
+		line, err := makeLineString(inCords[:l], precisions, dimension)
+		if err != nil {
+			return nil, err
+		}
+		lines[i] = line
 		inCords = inCords[l:]
 	}
-	return geometry.MultiLineString(lines)
+	return geometry.MultiLineString(lines), nil
 }
 
-func makeRing(inCords []int64, precision uint32, dimension uint32) geometry.Ring {
-	points := makeLine(inCords, precision, dimension, true)
+func makeRing(inCords []int64, precisions []uint32, dimension uint32) (geometry.Ring, error) {
+	points, err := makeLine(inCords, precisions, dimension, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, fieldError("lengths", fmt.Errorf("%w: empty ring", ErrInvalidLengths))
+	}
 	points = append(points, points[0])
-	return geometry.Ring(points)
+	return geometry.Ring(points), nil
 }
 
-func makeLineString(inCords []int64, precision uint32, dimension uint32) geometry.LineString {
-	return geometry.LineString(makeLine(inCords, precision, dimension, false))
+func makeLineString(inCords []int64, precisions []uint32, dimension uint32) (geometry.LineString, error) {
+	points, err := makeLine(inCords, precisions, dimension, false)
+	return geometry.LineString(points), err
 }
 
-func makeLine(inCords []int64, precision uint32, dimension uint32, isClosed bool) []geometry.Point {
-	points := make([]geometry.Point, len(inCords)/int(dimension))
-	prevCords := [2]int64{}
-	for i, j := 0, 1; j < len(inCords); i, j = i+2, j+2 {
-		prevCords[0] += inCords[i]
-		prevCords[1] += inCords[j]
-		points[i/2] = makePoint(prevCords[:], precision)
+func makeLine(inCords []int64, precisions []uint32, dimension uint32, isClosed bool) ([]geometry.Point, error) {
+	if dimension < 2 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidDimension, dimension)
+	}
+	// The dimension comes straight from the message, so nothing is sized
+	// by it until there are coordinates to check it against
+	if len(inCords) == 0 {
+		return []geometry.Point{}, nil
+	}
+	dim := int(dimension)
+	if dim > len(inCords) || len(inCords)%dim != 0 {
+		return nil, fieldError("coords", fmt.Errorf("%w: %d coordinates is not a multiple of dimension %d", ErrInvalidLengths, len(inCords), dimension))
+	}
+
+	// Each axis is delta encoded against the same axis of the previous point
+	points := make([]geometry.Point, len(inCords)/dim)
+	prevCords := make([]int64, dim)
+	for i := range points {
+		for j := range prevCords {
+			prevCords[j] += inCords[i*dim+j]
+		}
+		points[i] = geometry.Point(makeCoords(prevCords, precisions))
 	}
-	return points
+	return points, nil
 }
 
-func makeCoords(inCords []int64, precision uint32) []float64 {
+func makeCoords(inCords []int64, precisions []uint32) []float64 {
 	ret := make([]float64, len(inCords))
-	e := math.DecodePrecision(precision)
-
 	for i, val := range inCords {
+		e := math.DecodePrecision(precisions[i])
 		ret[i] = math.FloatWithPrecision(val, uint32(e))
 	}
 	return ret
 }
+
+// dimensionPrecisions returns the precision of each dimension, which is the
+// message's precision unless it's overridden in dimension_precisions. Points
+// never span more dimensions than there are coordinates, so a malformed
+// dimension can't make this allocate more than n values.
+func dimensionPrecisions(msg *proto.Data, precision, dimensions uint32, n int) []uint32 {
+	if int64(dimensions) < int64(n) {
+		n = int(dimensions)
+	}
+	precisions := make([]uint32, n)
+	for i := range precisions {
+		precisions[i] = precision
+		if overrides := msg.GetDimensionPrecisions(); i < len(overrides) {
+			precisions[i] = overrides[i]
+		}
+	}
+	return precisions
+}
+
+// countCoords returns how many coordinate values the given point counts
+// span, or false if that's more than available. The counts come straight
+// from the message, so each is checked against what's left before it's
+// added rather than trusted to fit an int.
+func countCoords(lengths []uint32, dimension uint32, available int) (int, bool) {
+	var n uint64
+	for _, length := range lengths {
+		size := uint64(length) * uint64(dimension)
+		if size > uint64(available)-n {
+			return 0, false
+		}
+		n += size
+	}
+	return int(n), true
+}
+
+func checkLengths(lengths []uint32, inCords []int64, dimension uint32) error {
+	n, ok := countCoords(lengths, dimension, len(inCords))
+	if !ok {
+		return fieldError("lengths", fmt.Errorf("%w: lengths describe more than the %d coordinates", ErrInvalidLengths, len(inCords)))
+	}
+	if n != len(inCords) {
+		return fieldError("lengths", fmt.Errorf("%w: lengths describe %d coordinates, got %d", ErrInvalidLengths, n, len(inCords)))
+	}
+	return nil
+}
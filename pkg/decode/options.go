@@ -0,0 +1,46 @@
+package decode
+
+import (
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+type DecodingConfig struct {
+	// Int64Numbers decodes integers as int64, falling back to uint64 for
+	// positive values beyond math.MaxInt64.
+	Int64Numbers bool
+	// RawJSON keeps JSON values as json.RawMessage instead of unmarshaling them
+	RawJSON bool
+	// Transform maps every decoded point, such as to reproject it. Nil
+	// leaves points as they are.
+	Transform func(geometry.Point) geometry.Point
+}
+
+type DecodingOption func(o *DecodingConfig)
+
+// WithInt64Numbers makes decoded number properties consistent Go types:
+// integers become int64 (or uint64 when above math.MaxInt64) and doubles
+// become float64, whatever width they were encoded from.
+func WithInt64Numbers() DecodingOption {
+	return func(o *DecodingConfig) {
+		o.Int64Numbers = true
+	}
+}
+
+// WithRawJSON leaves JSON encoded values, such as slices and maps, as
+// json.RawMessage rather than unmarshaling them.
+func WithRawJSON() DecodingOption {
+	return func(o *DecodingConfig) {
+		o.RawJSON = true
+	}
+}
+
+// WithTransform applies f to every point as it's decoded, so e.g.
+// geometry.WebMercatorToWGS84 reprojects coordinates in the same pass.
+// Coordinates encoded with encode.WithAffine are mapped back to the world
+// before f sees them. Bounding boxes are replaced by the bounds of their
+// transformed corners, like encode.WithTransform does.
+func WithTransform(f func(geometry.Point) geometry.Point) DecodingOption {
+	return func(o *DecodingConfig) {
+		o.Transform = f
+	}
+}
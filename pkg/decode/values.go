@@ -0,0 +1,90 @@
+package decode
+
+import (
+	"encoding/json"
+	"fmt"
+	gomath "math"
+
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+// DecodeValue converts a geobuf value back into a Go value. Doubles become
+// float64. By default positive integers become uint and negative integers
+// become int; see WithInt64Numbers for int64 instead. Negative integers
+// beyond math.MinInt64 can't be represented by either and become float64.
+// JSON values are unmarshaled into []interface{}, map[string]interface{} and
+// friends, unless WithRawJSON is set.
+func DecodeValue(val *proto.Data_Value, opts *DecodingConfig) (interface{}, error) {
+	switch actualVal := val.ValueType.(type) {
+	case *proto.Data_Value_BoolValue:
+		return actualVal.BoolValue, nil
+	case *proto.Data_Value_DoubleValue:
+		return actualVal.DoubleValue, nil
+	case *proto.Data_Value_StringValue:
+		return actualVal.StringValue, nil
+	case *proto.Data_Value_PosIntValue:
+		if !opts.Int64Numbers {
+			return uint(actualVal.PosIntValue), nil
+		}
+		if actualVal.PosIntValue > gomath.MaxInt64 {
+			return actualVal.PosIntValue, nil
+		}
+		return int64(actualVal.PosIntValue), nil
+	case *proto.Data_Value_NegIntValue:
+		return decodeNegInt(actualVal.NegIntValue, opts), nil
+	case *proto.Data_Value_JsonValue:
+		return decodeJSON(actualVal.JsonValue, opts)
+	}
+	return nil, ErrEmptyValue
+}
+
+func decodeNegInt(val uint64, opts *DecodingConfig) interface{} {
+	if val > 1<<63 {
+		return -float64(val)
+	}
+	// Negating in int64 also covers math.MinInt64, which wraps back onto itself
+	neg := -int64(val)
+	if opts.Int64Numbers {
+		return neg
+	}
+	return int(neg)
+}
+
+func decodeJSON(val string, opts *DecodingConfig) (interface{}, error) {
+	if opts.RawJSON {
+		return json.RawMessage(val), nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(val), &decoded); err != nil {
+		return nil, fmt.Errorf("json value: %w", err)
+	}
+	return decoded, nil
+}
+
+// decodeProperties resolves pairs of key/value indexes against the global
+// keys and the message's values.
+func decodeProperties(keys []string, values []*proto.Data_Value, pairs []uint32, opts *DecodingConfig) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("%w: %d", ErrOddProperties, len(pairs))
+	}
+
+	props := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i = i + 2 {
+		keyIdx := pairs[i]
+		valIdx := pairs[i+1]
+		if int(keyIdx) >= len(keys) {
+			return nil, fmt.Errorf("%w: %d of %d at pair %d", ErrKeyOutOfRange, keyIdx, len(keys), i/2)
+		}
+		if int(valIdx) >= len(values) {
+			return nil, fmt.Errorf("%w: %d of %d at pair %d", ErrValueOutOfRange, valIdx, len(values), i/2)
+		}
+
+		val, err := DecodeValue(values[valIdx], opts)
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %w", valIdx, err)
+		}
+		props[keys[keyIdx]] = val
+	}
+	return props, nil
+}
@@ -0,0 +1,164 @@
+package decode_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/decode"
+	"github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+func TestDecodeIntValue(t *testing.T) {
+	testCases := []struct {
+		Val      interface{}
+		Default  interface{}
+		Int64    interface{}
+		Encoding *proto.Data_Value
+	}{
+		{
+			Val:     1,
+			Default: uint(1),
+			Int64:   int64(1),
+		},
+		{
+			Val:     -1,
+			Default: -1,
+			Int64:   int64(-1),
+		},
+		{
+			Val:     int8(1),
+			Default: uint(1),
+			Int64:   int64(1),
+		},
+		{
+			Val:     int32(-5),
+			Default: -5,
+			Int64:   int64(-5),
+		},
+		{
+			Val:     uint16(16),
+			Default: uint(16),
+			Int64:   int64(16),
+		},
+		{
+			Val:     uint64(18446744073709551615),
+			Default: uint(18446744073709551615),
+			Int64:   uint64(18446744073709551615),
+		},
+		{
+			Val:     int64(-9223372036854775808),
+			Default: -9223372036854775808,
+			Int64:   int64(-9223372036854775808),
+		},
+		{
+			Val:     int64(9223372036854775807),
+			Default: uint(9223372036854775807),
+			Int64:   int64(9223372036854775807),
+		},
+		{
+			Val:     uint64(9223372036854775808),
+			Default: uint(9223372036854775808),
+			Int64:   uint64(9223372036854775808),
+		},
+		{
+			Val:     float32(12.5),
+			Default: float64(12.5),
+			Int64:   float64(12.5),
+		},
+		// Neg int values past math.MinInt64 can only come from other encoders
+		{
+			Encoding: &proto.Data_Value{
+				ValueType: &proto.Data_Value_NegIntValue{NegIntValue: 18446744073709551615},
+			},
+			Default: float64(-18446744073709551615),
+			Int64:   float64(-18446744073709551615),
+		},
+	}
+
+	for i, test := range testCases {
+		val := test.Encoding
+		if val == nil {
+			var err error
+			val, err = encode.EncodeValue(test.Val)
+			if err != nil {
+				t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+			}
+		}
+
+		decoded, err := DecodeValue(val, &DecodingConfig{})
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Default, decoded) {
+			t.Errorf("Case [%d]: Expected %T(%v), got %T(%v)", i, test.Default, test.Default, decoded, decoded)
+		}
+
+		decoded, err = DecodeValue(val, &DecodingConfig{Int64Numbers: true})
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Int64, decoded) {
+			t.Errorf("Case [%d]: Expected %T(%v), got %T(%v)", i, test.Int64, test.Int64, decoded, decoded)
+		}
+	}
+}
+
+func TestDecodeJsonValue(t *testing.T) {
+	testCases := []struct {
+		Val      interface{}
+		Expected interface{}
+		Raw      json.RawMessage
+	}{
+		{
+			Val:      []string{"A", "B", "C"},
+			Expected: []interface{}{"A", "B", "C"},
+			Raw:      json.RawMessage(`["A","B","C"]`),
+		},
+		{
+			Val:      map[string]int{"1": 1},
+			Expected: map[string]interface{}{"1": float64(1)},
+			Raw:      json.RawMessage(`{"1":1}`),
+		},
+		{
+			Val: map[string]interface{}{"nested": []interface{}{true, nil}},
+			Expected: map[string]interface{}{
+				"nested": []interface{}{true, nil},
+			},
+			Raw: json.RawMessage(`{"nested":[true,null]}`),
+		},
+	}
+
+	for i, test := range testCases {
+		val, err := encode.EncodeValue(test.Val)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		decoded, err := DecodeValue(val, &DecodingConfig{})
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Expected, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test.Expected, decoded)
+		}
+
+		decoded, err = DecodeValue(val, &DecodingConfig{RawJSON: true})
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Raw, decoded) {
+			t.Errorf("Case [%d]: Expected %s, got %s", i, test.Raw, decoded)
+		}
+	}
+}
+
+func TestDecodeInvalidJsonValue(t *testing.T) {
+	val := &proto.Data_Value{
+		ValueType: &proto.Data_Value_JsonValue{JsonValue: "[1,"},
+	}
+	if decoded, err := DecodeValue(val, &DecodingConfig{}); err == nil {
+		t.Errorf("Expected an error, got %+v", decoded)
+	}
+}
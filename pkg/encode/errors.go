@@ -0,0 +1,61 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	gomath "math"
+
+	"github.com/cairnapp/go-geobuf/pkg/math"
+)
+
+var (
+	ErrInvalidCoordinate  = errors.New("coordinate is not a finite number")
+	ErrCoordinateOverflow = errors.New("coordinate overflows at this precision")
+	ErrEmptyRing          = errors.New("ring has no coordinates")
+)
+
+// CoordinateError reports a coordinate that can't be encoded at the given
+// precision, or a ring without any coordinates, which decoders reject.
+type CoordinateError struct {
+	Value     float64
+	Precision uint
+	Err       error
+}
+
+func (e *CoordinateError) Error() string {
+	if errors.Is(e.Err, ErrEmptyRing) {
+		return e.Err.Error()
+	}
+	if errors.Is(e.Err, ErrCoordinateOverflow) {
+		if max := math.MaxPrecisionFor(e.Value); max > 0 {
+			return fmt.Sprintf("coordinate %v: %s of %d digits, use at most %d", e.Value, e.Err, math.EncodePrecision(e.Precision), math.EncodePrecision(max))
+		}
+	}
+	return fmt.Sprintf("coordinate %v: %s", e.Value, e.Err)
+}
+
+func (e *CoordinateError) Unwrap() error {
+	return e.Err
+}
+
+// FeatureError reports which feature of a collection could not be encoded.
+type FeatureError struct {
+	Index int
+	Err   error
+}
+
+func (e *FeatureError) Error() string {
+	return fmt.Sprintf("feature %d: %s", e.Index, e.Err)
+}
+
+func (e *FeatureError) Unwrap() error {
+	return e.Err
+}
+
+func coordinateError(value float64, precision uint) error {
+	err := ErrCoordinateOverflow
+	if gomath.IsNaN(value) || gomath.IsInf(value, 0) {
+		err = ErrInvalidCoordinate
+	}
+	return &CoordinateError{Value: value, Precision: precision, Err: err}
+}
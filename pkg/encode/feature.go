@@ -6,38 +6,55 @@ import (
 )
 
 func EncodeFeature(feature *geojson.Feature, opts *EncodingConfig) (*proto.Data_Feature, error) {
-	oldGeo := geojson.NewGeometry(feature.Geometry)
-	geo := EncodeGeometry(oldGeo, opts)
-	f := &proto.Data_Feature{
-		Geometry: geo,
+	values := newValueStore()
+	f, err := encodeFeature(feature, opts, values)
+	if err != nil {
+		return f, err
 	}
+	f.Values = values.Values()
+	return f, nil
+}
 
-	id, err := EncodeIntId(feature.ID)
-	if err == nil {
-		f.IdType = id
-	} else {
-		newId, newErr := EncodeId(feature.ID)
-		if newErr != nil {
-			return nil, newErr
+// encodeFeature encodes a feature, adding its values to the given store
+// rather than to the feature itself.
+func encodeFeature(feature *geojson.Feature, opts *EncodingConfig, values *valueStore) (*proto.Data_Feature, error) {
+	f := &proto.Data_Feature{}
+	if geo := feature.GeometryObject(); geo != nil {
+		encoded, err := EncodeGeometry(geo, opts)
+		if err != nil {
+			return nil, err
 		}
-		f.IdType = newId
+		f.Geometry = encoded
 	}
 
-	properties := make([]uint32, 0, 2*len(feature.Properties))
-	values := make([]*proto.Data_Value, 0, len(feature.Properties))
-	for key, val := range feature.Properties {
-		encoded, err := EncodeValue(val)
-		if err != nil {
-			return f, err
+	// Features without an ID are written without one, rather than with the
+	// string ID "null" that marshalling nil would give them
+	if feature.ID != nil {
+		id, err := EncodeIntId(feature.ID)
+		if err == nil {
+			f.IdType = id
+		} else {
+			newId, newErr := EncodeId(feature.ID)
+			if newErr != nil {
+				return nil, newErr
+			}
+			f.IdType = newId
 		}
+	}
 
-		idx := opts.Keys.IndexOf(key)
-		values = append(values, encoded)
-		properties = append(properties, uint32(idx))
-		properties = append(properties, uint32(len(values)-1))
+	// Properties and custom properties share the feature's values
+	properties, err := encodeProperties(feature.Properties, opts, values)
+	if err != nil {
+		return f, err
+	}
+	custom, err := encodeProperties(withBBox(feature.Custom, feature.BBox.Transform(opts.Transform)), opts, values)
+	if err != nil {
+		return f, err
 	}
 
-	f.Values = values
 	f.Properties = properties
+	if len(custom) > 0 {
+		f.CustomProperties = custom
+	}
 	return f, nil
 }
@@ -8,15 +8,38 @@ import (
 func EncodeFeatureCollection(collection *geojson.FeatureCollection, opts *EncodingConfig) (*proto.Data_FeatureCollection, error) {
 	features := make([]*proto.Data_Feature, len(collection.Features))
 
+	var shared *valueStore
+	if opts.SharedValues {
+		shared = newValueStore()
+	}
 	for i, feature := range collection.Features {
-		encoded, err := EncodeFeature(feature, opts)
+		var encoded *proto.Data_Feature
+		var err error
+		if shared != nil {
+			encoded, err = encodeFeature(feature, opts, shared)
+		} else {
+			encoded, err = EncodeFeature(feature, opts)
+		}
 		if err != nil {
-			return nil, err
+			return nil, &FeatureError{Index: i, Err: err}
 		}
 		features[i] = encoded
 	}
 
-	return &proto.Data_FeatureCollection{
+	encoded := &proto.Data_FeatureCollection{
 		Features: features,
-	}, nil
+	}
+	values := shared
+	if values == nil {
+		values = newValueStore()
+	}
+	if custom := withBBox(collection.Custom, collection.BBox.Transform(opts.Transform)); len(custom) > 0 {
+		custom, err := encodeProperties(custom, opts, values)
+		if err != nil {
+			return nil, err
+		}
+		encoded.CustomProperties = custom
+	}
+	encoded.Values = values.Values()
+	return encoded, nil
 }
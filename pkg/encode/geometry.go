@@ -16,86 +16,239 @@ const (
 	GeometryMultiPolygon    = "MultiPolygon"
 )
 
-func EncodeGeometry(g *geojson.Geometry, opt *EncodingConfig) *proto.Data_Geometry {
+func EncodeGeometry(g *geojson.Geometry, opt *EncodingConfig) (*proto.Data_Geometry, error) {
+	// Validation looks at what's actually encoded, so that e.g. a polygon
+	// simplified into an invalid one is caught too
+	coordinates, project := prepareCoordinates(g.Geometry(), opt)
+	if opt.Validate {
+		if err := validateGeometry(coordinates, opt); err != nil {
+			return nil, err
+		}
+	}
+	return encodeGeometryTree(g, coordinates, project, opt)
+}
+
+// encodeGeometryTree encodes g with the prepared coordinates, which for
+// collections hold those of each child in turn.
+func encodeGeometryTree(g *geojson.Geometry, coordinates geometry.Geometry, project projection, opt *EncodingConfig) (*proto.Data_Geometry, error) {
+	geo, err := encodeGeometry(g, coordinates, project, opt)
+	if geo == nil || err != nil {
+		return nil, err
+	}
+	if g.Type == geojson.GeometryCollectionType {
+		children := coordinates.(geometry.Collection)
+		geo.Geometries = make([]*proto.Data_Geometry, len(g.Geometries))
+		for i, child := range g.Geometries {
+			encoded, err := encodeGeometryTree(child, children[i], project, opt)
+			if err != nil {
+				return nil, err
+			}
+			geo.Geometries[i] = encoded
+		}
+	}
+	if custom := withBBox(g.Custom, g.BBox.Transform(opt.Transform)); len(custom) > 0 {
+		values := newValueStore()
+		custom, err := encodeProperties(custom, opt, values)
+		if err != nil {
+			return nil, err
+		}
+		geo.Values = values.Values()
+		geo.CustomProperties = custom
+	}
+	return geo, nil
+}
+
+func encodeGeometry(g *geojson.Geometry, coordinates geometry.Geometry, project projection, opt *EncodingConfig) (*proto.Data_Geometry, error) {
+	precisions := opt.Precisions()
 	switch g.Type {
 	case geojson.GeometryPointType:
-		p := g.Coordinates.(geometry.Point)
+		p := coordinates.(geometry.Point)
+		coords, err := translateCoords(precisions, project, p)
 		return &proto.Data_Geometry{
 			Type:   proto.Data_Geometry_POINT,
-			Coords: translateCoords(opt.Precision, p[:]),
-		}
+			Coords: coords,
+		}, err
 	case geojson.GeometryMultiPointType:
-		p := g.Coordinates.(geometry.MultiPoint)
+		p := coordinates.(geometry.MultiPoint)
+		coords, err := translateLine(precisions, project, p, false)
 		return &proto.Data_Geometry{
 			Type:   proto.Data_Geometry_MULTIPOINT,
-			Coords: translateLine(opt.Precision, opt.Dimension, p, false),
-		}
+			Coords: coords,
+		}, err
 	case geojson.GeometryLineStringType:
-		p := g.Coordinates.(geometry.LineString)
+		p := coordinates.(geometry.LineString)
+		coords, err := translateLine(precisions, project, p, false)
 		return &proto.Data_Geometry{
 			Type:   proto.Data_Geometry_LINESTRING,
-			Coords: translateLine(opt.Precision, opt.Dimension, p, false),
-		}
+			Coords: coords,
+		}, err
 	case geojson.GeometryMultiLineStringType:
-		p := g.Coordinates.(geometry.MultiLineString)
-		coords, lengths := translateMultiLine(opt.Precision, opt.Dimension, p)
+		p := coordinates.(geometry.MultiLineString)
+		coords, lengths, err := translateMultiLine(precisions, project, p)
 		return &proto.Data_Geometry{
 			Type:    proto.Data_Geometry_MULTILINESTRING,
 			Coords:  coords,
 			Lengths: lengths,
-		}
+		}, err
 	case geojson.GeometryPolygonType:
-		p := []geometry.Ring(g.Coordinates.(geometry.Polygon))
-		coords, lengths := translateMultiRing(opt.Precision, opt.Dimension, p)
+		p := coordinates.(geometry.Polygon)
+		coords, lengths, err := translateMultiRing(precisions, project, p)
 		return &proto.Data_Geometry{
 			Type:    proto.Data_Geometry_POLYGON,
 			Coords:  coords,
 			Lengths: lengths,
-		}
+		}, err
 	case geojson.GeometryMultiPolygonType:
-		p := []geometry.Polygon(g.Coordinates.(geometry.MultiPolygon))
-		coords, lengths := translateMultiPolygon(opt.Precision, opt.Dimension, p)
+		p := coordinates.(geometry.MultiPolygon)
+		coords, lengths, err := translateMultiPolygon(precisions, project, p)
 		return &proto.Data_Geometry{
 			Type:    proto.Data_Geometry_MULTIPOLYGON,
 			Coords:  coords,
 			Lengths: lengths,
+		}, err
+	case geojson.GeometryCollectionType:
+		return &proto.Data_Geometry{
+			Type: proto.Data_Geometry_GEOMETRYCOLLECTION,
+		}, nil
+	}
+	return nil, nil
+}
+
+// prepareCoordinates simplifies and rewinds the coordinates of g and of any
+// geometries it contains as the config asks, returning them along with the
+// projection that's left to apply to each point as it's encoded. Points are
+// only transformed up front when they're simplified, rewound or validated,
+// and are otherwise left for the projection to transform one at a time.
+// Either way the prepared coordinates are in world coordinates: moving them
+// onto the affine transform's grid is left until they're encoded.
+func prepareCoordinates(g geometry.Geometry, opt *EncodingConfig) (geometry.Geometry, projection) {
+	if g == nil {
+		return nil, nil
+	}
+	transformed := opt.Transform != nil && (opt.SimplifyTolerance > 0 || opt.Rewind || opt.Validate)
+	if transformed {
+		g = geometry.Transform(g, opt.Transform)
+	}
+	return shapeCoordinates(g, opt), opt.projection(transformed)
+}
+
+// projection maps a point onto the coordinates written for it, nil writes
+// points as they are.
+type projection func(geometry.Point) geometry.Point
+
+func (p projection) apply(point geometry.Point) geometry.Point {
+	if p == nil {
+		return point
+	}
+	return p(point)
+}
+
+// projection returns what's left to do to each point as it's encoded, given
+// whether the points have been transformed already.
+func (o *EncodingConfig) projection(transformed bool) projection {
+	transform := o.Transform
+	if transformed {
+		transform = nil
+	}
+	switch {
+	case o.Affine == nil:
+		return transform
+	case transform == nil:
+		return o.Affine.Invert
+	}
+	affine := *o.Affine
+	return func(point geometry.Point) geometry.Point {
+		return affine.Invert(transform(point))
+	}
+}
+
+// shapeCoordinates simplifies and rewinds g as the config asks.
+func shapeCoordinates(g geometry.Geometry, opt *EncodingConfig) geometry.Geometry {
+	if c, ok := g.(geometry.Collection); ok {
+		shaped := make(geometry.Collection, len(c))
+		for i, child := range c {
+			shaped[i] = shapeCoordinates(child, opt)
+		}
+		return shaped
+	}
+	if opt.SimplifyTolerance > 0 {
+		switch t := g.(type) {
+		case geometry.LineString:
+			g = t.Simplify(opt.SimplifyTolerance)
+		case geometry.MultiLineString:
+			g = t.Simplify(opt.SimplifyTolerance)
+		case geometry.Polygon:
+			g = simplifyPolygon(t, opt.SimplifyTolerance)
+		case geometry.MultiPolygon:
+			simplified := make(geometry.MultiPolygon, len(t))
+			for i, polygon := range t {
+				simplified[i] = simplifyPolygon(polygon, opt.SimplifyTolerance)
+			}
+			g = simplified
+		}
+	}
+	if opt.Rewind {
+		switch t := g.(type) {
+		case geometry.Polygon:
+			g = t.Rewind(opt.RewindRFC7946)
+		case geometry.MultiPolygon:
+			g = t.Rewind(opt.RewindRFC7946)
 		}
 	}
-	return nil
+	return g
 }
 
-func translateMultiLine(e uint, dim uint, lines []geometry.LineString) ([]int64, []uint32) {
+func translateMultiLine(precisions []uint, project projection, lines []geometry.LineString) ([]int64, []uint32, error) {
 	lengths := make([]uint32, len(lines))
 	coords := []int64{}
 
 	for i, line := range lines {
 		lengths[i] = uint32(len(line))
-		coords = append(coords, translateLine(e, dim, line, false)...)
+		newLine, err := translateLine(precisions, project, line, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		coords = append(coords, newLine...)
 	}
-	return coords, lengths
+	return coords, lengths, nil
 }
 
-func translateMultiPolygon(e uint, dim uint, polygons []geometry.Polygon) ([]int64, []uint32) {
+func translateMultiPolygon(precisions []uint, project projection, polygons []geometry.Polygon) ([]int64, []uint32, error) {
 	lengths := []uint32{uint32(len(polygons))}
 	coords := []int64{}
 	for _, rings := range polygons {
 		lengths = append(lengths, uint32(len(rings)))
-		newLine, newLength := translateMultiRing(e, dim, rings)
+		newLine, newLength, err := translateMultiRing(precisions, project, rings)
+		if err != nil {
+			return nil, nil, err
+		}
 		lengths = append(lengths, newLength...)
 		coords = append(coords, newLine...)
 	}
-	return coords, lengths
+	return coords, lengths, nil
 }
 
-func translateMultiRing(e uint, dim uint, lines []geometry.Ring) ([]int64, []uint32) {
+func translateMultiRing(precisions []uint, project projection, lines []geometry.Ring) ([]int64, []uint32, error) {
 	lengths := make([]uint32, len(lines))
 	coords := []int64{}
 	for i, line := range lines {
-		lengths[i] = uint32(len(line) - 1)
-		newLine := translateLine(e, dim, line, true)
+		if len(line) == 0 {
+			return nil, nil, &CoordinateError{Err: ErrEmptyRing}
+		}
+		// Decoding restores the closing point, so it's only left out when
+		// the ring actually has one
+		closed := line.IsClosed()
+		lengths[i] = uint32(len(line))
+		if closed {
+			lengths[i]--
+		}
+		newLine, err := translateLine(precisions, project, line, closed)
+		if err != nil {
+			return nil, nil, err
+		}
 		coords = append(coords, newLine...)
 	}
-	return coords, lengths
+	return coords, lengths, nil
 }
 
 /*
@@ -114,28 +267,52 @@ such as with a polygon, we can skip the last point, and place it back when we de
 
 1. https://developers.google.com/protocol-buffers/docs/encoding#varints
 */
-func translateLine(precision uint, dim uint, points []geometry.Point, isClosed bool) []int64 {
+func translateLine(precisions []uint, project projection, points []geometry.Point, isClosed bool) ([]int64, error) {
+	dim := len(precisions)
 	sums := make([]int64, dim)
-	ret := make([]int64, len(points)*int(dim))
+	ret := make([]int64, len(points)*dim)
 	for i, point := range points {
-		for j, p := range point {
-			n := math.IntWithPrecision(p, precision) - sums[j]
-			ret[(int(dim)*i)+j] = n
+		point = project.apply(point)
+		for j := range sums {
+			coord, err := coordAt(point, j, precisions[j])
+			if err != nil {
+				return nil, err
+			}
+			n := coord - sums[j]
+			ret[(dim*i)+j] = n
 			sums[j] = sums[j] + n
 		}
 	}
 	if isClosed {
-		return ret[:(len(ret) - int(dim))]
+		return ret[:(len(ret) - dim)], nil
 	}
-	return ret
+	return ret, nil
 }
 
 // Converts a floating point geojson point to int64 by multiplying it by a factor of 10,
 // potentially truncating and rounding
-func translateCoords(precision uint, point []float64) []int64 {
-	ret := make([]int64, len(point))
-	for i, p := range point {
-		ret[i] = math.IntWithPrecision(p, precision)
+func translateCoords(precisions []uint, project projection, point geometry.Point) ([]int64, error) {
+	point = project.apply(point)
+	ret := make([]int64, len(precisions))
+	for i := range ret {
+		coord, err := coordAt(point, i, precisions[i])
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = coord
+	}
+	return ret, nil
+}
+
+// Points with fewer coordinates than the encoded dimension are padded with 0,
+// and any coordinates beyond it are dropped. Coordinates that would overflow
+// once scaled, or aren't numbers at all, are rejected.
+func coordAt(point []float64, i int, precision uint) (int64, error) {
+	if i >= len(point) {
+		return 0, nil
+	}
+	if !math.CanScale(point[i], precision) {
+		return 0, coordinateError(point[i], precision)
 	}
-	return ret
+	return math.IntWithPrecision(point[i], precision), nil
 }
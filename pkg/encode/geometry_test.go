@@ -1,6 +1,9 @@
 package encode_test
 
 import (
+	"errors"
+	"fmt"
+	gomath "math"
 	"reflect"
 	"testing"
 
@@ -41,10 +44,13 @@ func TestEncodePoint(t *testing.T) {
 			Type:   proto.Data_Geometry_POINT,
 			Coords: test.Expected,
 		}
-		encoded := EncodeGeometry(p, &EncodingConfig{
+		encoded, err := EncodeGeometry(p, &EncodingConfig{
 			Dimension: 2,
 			Precision: test.Precision,
 		})
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
 
 		if !reflect.DeepEqual(encoded, expected) {
 			t.Errorf("Case [%d]: Expected %+v, got %+v", i, expected, encoded)
@@ -52,3 +58,423 @@ func TestEncodePoint(t *testing.T) {
 	}
 
 }
+
+func TestEncodeGeometryCollection(t *testing.T) {
+	p := geojson.NewGeometry(geometry.Collection{
+		geometry.Point([]float64{124.123, 234.456}),
+		geometry.Collection{
+			geometry.LineString([]geometry.Point{
+				geometry.Point([]float64{124.123, 234.456}),
+				geometry.Point([]float64{124.124, 234.458}),
+			}),
+		},
+	})
+	expected := &proto.Data_Geometry{
+		Type: proto.Data_Geometry_GEOMETRYCOLLECTION,
+		Geometries: []*proto.Data_Geometry{
+			{
+				Type:   proto.Data_Geometry_POINT,
+				Coords: []int64{124123, 234456},
+			},
+			{
+				Type: proto.Data_Geometry_GEOMETRYCOLLECTION,
+				Geometries: []*proto.Data_Geometry{
+					{
+						Type:   proto.Data_Geometry_LINESTRING,
+						Coords: []int64{124123, 234456, 1, 2},
+					},
+				},
+			},
+		},
+	}
+	encoded, err := EncodeGeometry(p, &EncodingConfig{
+		Dimension: 2,
+		Precision: 1000,
+	})
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	if !reflect.DeepEqual(encoded, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, encoded)
+	}
+}
+
+func TestEncodeLineDimensions(t *testing.T) {
+	p := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+		geometry.Point([]float64{1, 2, 3}),
+		geometry.Point([]float64{2, 4, 2}),
+		geometry.Point([]float64{4, 5}),
+	}))
+	expected := &proto.Data_Geometry{
+		Type:   proto.Data_Geometry_LINESTRING,
+		Coords: []int64{1, 2, 3, 1, 2, -1, 2, 1, -2},
+	}
+	encoded, err := EncodeGeometry(p, &EncodingConfig{
+		Dimension: 3,
+		Precision: 1,
+	})
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	if !reflect.DeepEqual(encoded, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, encoded)
+	}
+}
+
+func TestEncodeCoordinateLimits(t *testing.T) {
+	testCases := []struct {
+		Value     float64
+		Precision uint
+		Expected  error
+	}{
+		// Web Mercator metres fit at 1e9, up to 2^62
+		{20037508.342789244, 1e9, nil},
+		{-20037508.342789244, 1e9, nil},
+		{4.6e9, 1e9, nil},
+		{4.7e9, 1e9, ErrCoordinateOverflow},
+		{-4.7e9, 1e9, ErrCoordinateOverflow},
+		{4.7e9, 1e8, nil},
+		{1 << 62, 1, ErrCoordinateOverflow},
+		{gomath.NaN(), 1, ErrInvalidCoordinate},
+		{gomath.Inf(-1), 1, ErrInvalidCoordinate},
+	}
+
+	for i, test := range testCases {
+		p := geojson.NewGeometry(geometry.LineString([]geometry.Point{
+			geometry.Point([]float64{0, 0}),
+			geometry.Point([]float64{test.Value, 0}),
+		}))
+		_, err := EncodeGeometry(p, &EncodingConfig{
+			Dimension: 2,
+			Precision: test.Precision,
+		})
+		if !errors.Is(err, test.Expected) {
+			t.Errorf("Case [%d]: Expected %v for %v, got %v", i, test.Expected, test.Value, err)
+		}
+	}
+}
+
+func TestEncodeCoordinateOverflowMessage(t *testing.T) {
+	p := geojson.NewGeometry(geometry.Point([]float64{4.7e9, 0}))
+	_, err := EncodeGeometry(p, &EncodingConfig{
+		Dimension: 2,
+		Precision: 1e9,
+	})
+
+	expected := "coordinate 4.7e+09: coordinate overflows at this precision of 9 digits, use at most 8"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+func TestEncodeRingClosure(t *testing.T) {
+	testCases := []struct {
+		Ring     geometry.Ring
+		Expected *proto.Data_Geometry
+	}{
+		{
+			Ring: geometry.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			Expected: &proto.Data_Geometry{
+				Type:    proto.Data_Geometry_POLYGON,
+				Coords:  []int64{0, 0, 1, 0, 0, 1},
+				Lengths: []uint32{3},
+			},
+		},
+		// Only a ring that ends where it starts loses its last point
+		{
+			Ring: geometry.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 1}},
+			Expected: &proto.Data_Geometry{
+				Type:    proto.Data_Geometry_POLYGON,
+				Coords:  []int64{0, 0, 1, 0, 0, 1, -1, 0},
+				Lengths: []uint32{4},
+			},
+		},
+	}
+
+	for i, test := range testCases {
+		encoded, err := EncodeGeometry(geojson.NewGeometry(geometry.Polygon{test.Ring}), &EncodingConfig{
+			Dimension: 2,
+			Precision: 1,
+		})
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Expected, encoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test.Expected, encoded)
+		}
+	}
+}
+
+func TestEncodeValidation(t *testing.T) {
+	unclosed := geojson.NewGeometry(geometry.Collection{
+		geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}},
+	})
+	bowTie := geojson.NewGeometry(geometry.Polygon{{{0, 0}, {1, 1}, {1, 0}, {0, 1}}})
+
+	testCases := []struct {
+		Geometry *geojson.Geometry
+		Options  []EncodingOption
+		Expected string
+	}{
+		{Geometry: unclosed},
+		{
+			Geometry: unclosed,
+			Options:  []EncodingOption{WithValidation()},
+			Expected: "geometries[0].coordinates[0]: ring is not closed",
+		},
+		{
+			Geometry: unclosed,
+			Options:  []EncodingOption{WithValidation(), WithAutoClose()},
+		},
+		{
+			Geometry: bowTie,
+			Options:  []EncodingOption{WithValidation(), WithAutoClose()},
+			Expected: "coordinates[0]: ring intersects itself: edges 0 and 2",
+		},
+	}
+
+	for i, test := range testCases {
+		cfg := &EncodingConfig{Dimension: 2, Precision: 1}
+		for _, opt := range test.Options {
+			opt(cfg)
+		}
+		_, err := EncodeGeometry(test.Geometry, cfg)
+		if test.Expected == "" && err != nil {
+			t.Errorf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if test.Expected != "" && (err == nil || err.Error() != test.Expected) {
+			t.Errorf("Case [%d]: Expected %q, got %v", i, test.Expected, err)
+		}
+	}
+}
+
+func TestEncodeRewind(t *testing.T) {
+	clockwise := geometry.Polygon{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Options  []EncodingOption
+		Expected []int64
+	}{
+		{
+			Geometry: clockwise,
+			Expected: []int64{0, 0, 0, 1, 1, 0},
+		},
+		{
+			Geometry: clockwise,
+			Options:  []EncodingOption{WithRewind(false)},
+			Expected: []int64{0, 0, 0, 1, 1, 0},
+		},
+		{
+			Geometry: clockwise,
+			Options:  []EncodingOption{WithRewind(true)},
+			Expected: []int64{0, 0, 1, 1, -1, 0},
+		},
+		{
+			Geometry: geometry.MultiPolygon{clockwise},
+			Options:  []EncodingOption{WithRewind(true)},
+			Expected: []int64{0, 0, 1, 1, -1, 0},
+		},
+	}
+
+	for i, test := range testCases {
+		cfg := &EncodingConfig{Dimension: 2, Precision: 1}
+		for _, opt := range test.Options {
+			opt(cfg)
+		}
+		encoded, err := EncodeGeometry(geojson.NewGeometry(test.Geometry), cfg)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Expected, encoded.Coords) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, encoded.Coords)
+		}
+	}
+}
+
+func TestEncodeSimplification(t *testing.T) {
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Expected []int64
+	}{
+		{
+			Geometry: geometry.LineString{{0, 0}, {5, 1}, {10, 0}},
+			Expected: []int64{0, 0, 10, 0},
+		},
+		{
+			Geometry: geometry.MultiLineString{{{0, 0}, {5, 1}, {10, 0}}},
+			Expected: []int64{0, 0, 10, 0},
+		},
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {5, 1}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+			Expected: []int64{0, 0, 10, 0, 0, 10, -10, 0},
+		},
+		// Rings are never simplified away
+		{
+			Geometry: geometry.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+			Expected: []int64{0, 0, 1, 0, 0, 1},
+		},
+	}
+
+	for i, test := range testCases {
+		cfg := &EncodingConfig{Dimension: 2, Precision: 1}
+		WithSimplification(2)(cfg)
+		encoded, err := EncodeGeometry(geojson.NewGeometry(test.Geometry), cfg)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Expected, encoded.Coords) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, encoded.Coords)
+		}
+	}
+}
+
+func TestEncodeTransform(t *testing.T) {
+	var calls int
+	shift := func(p geometry.Point) geometry.Point {
+		calls++
+		return geometry.Point{p[0] + 10, p[1]}
+	}
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Options  []EncodingOption
+		Expected []int64
+		Calls    int
+	}{
+		{
+			Geometry: geometry.Point{1, 2},
+			Expected: []int64{11, 2},
+			Calls:    1,
+		},
+		{
+			Geometry: geometry.LineString{{0, 0}, {1, 1}},
+			Expected: []int64{10, 0, 1, 1},
+			Calls:    2,
+		},
+		// Rings stay closed, so their closing point is still left out
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			Expected: []int64{10, 0, 1, 0, 0, 1},
+			Calls:    4,
+		},
+		// Validating needs the transformed points first, but still
+		// transforms each of them only once
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			Options:  []EncodingOption{WithValidation(), WithAffine(geometry.Affine{Scale: [2]float64{1, 1}})},
+			Expected: []int64{10, 0, 1, 0, 0, 1},
+			Calls:    4,
+		},
+	}
+
+	for i, test := range testCases {
+		cfg := &EncodingConfig{Dimension: 2, Precision: 1}
+		WithTransform(shift)(cfg)
+		for _, opt := range test.Options {
+			opt(cfg)
+		}
+		calls = 0
+		encoded, err := EncodeGeometry(geojson.NewGeometry(test.Geometry), cfg)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Expected, encoded.Coords) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, encoded.Coords)
+		}
+		if calls != test.Calls {
+			t.Errorf("Case [%d]: Expected %d calls, got %d", i, test.Calls, calls)
+		}
+	}
+}
+
+func TestEncodeAffine(t *testing.T) {
+	affine := geometry.Affine{Scale: [2]float64{0.5, -0.5}, Translate: [2]float64{100, 200}}
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Expected []int64
+	}{
+		{
+			Geometry: geometry.Point{100.5, 199, 12.25},
+			Expected: []int64{1, 2, 1225},
+		},
+		// Points between grid points are rounded to the closest one
+		{
+			Geometry: geometry.LineString{{100, 200, 0}, {101.2, 198.9, 0}},
+			Expected: []int64{0, 0, 0, 2, 2, 0},
+		},
+		{
+			Geometry: geometry.Polygon{{{100, 200}, {102, 200}, {102, 198}, {100, 200}}},
+			Expected: []int64{0, 0, 0, 4, 0, 0, 0, 4, 0},
+		},
+	}
+
+	for i, test := range testCases {
+		cfg := &EncodingConfig{Dimension: 3, Precision: 100}
+		WithAffine(affine)(cfg)
+		if expected := []uint{1, 1, 100}; !reflect.DeepEqual(expected, cfg.Precisions()) {
+			t.Fatalf("Case [%d]: Expected precisions %v, got %v", i, expected, cfg.Precisions())
+		}
+		encoded, err := EncodeGeometry(geojson.NewGeometry(test.Geometry), cfg)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Expected, encoded.Coords) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, encoded.Coords)
+		}
+	}
+}
+
+func TestEncodeSimplificationKeepsPolygonsValid(t *testing.T) {
+	// Simplifying away the bump would leave the hole outside of the polygon
+	bump := geometry.Polygon{
+		{{0, 0}, {10, 0}, {10, 10}, {6, 10}, {5, 10.8}, {4, 10}, {0, 10}, {0, 0}},
+		{{4.8, 10.2}, {5.2, 10.2}, {5, 10.5}, {4.8, 10.2}},
+	}
+	testCases := []geometry.Geometry{bump, geometry.MultiPolygon{bump}}
+
+	for i, test := range testCases {
+		cfg := &EncodingConfig{Dimension: 2, Precision: 10}
+		expected, err := EncodeGeometry(geojson.NewGeometry(test), cfg)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+
+		WithValidation()(cfg)
+		WithSimplification(1)(cfg)
+		encoded, err := EncodeGeometry(geojson.NewGeometry(test), cfg)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(expected.Coords, encoded.Coords) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, expected.Coords, encoded.Coords)
+		}
+	}
+}
+
+func BenchmarkEncodeSimplification(b *testing.B) {
+	for _, n := range []int{2000, 4000, 8000} {
+		// A circle with a point halfway along each edge, which simplifying
+		// drops again
+		ring := make(geometry.Ring, 0, 2*n+1)
+		for i := 0; i < n; i++ {
+			a := 2 * gomath.Pi * float64(i) / float64(n)
+			c := 2 * gomath.Pi * float64(i+1) / float64(n)
+			start := geometry.Point{gomath.Cos(a), gomath.Sin(a)}
+			end := geometry.Point{gomath.Cos(c), gomath.Sin(c)}
+			ring = append(ring, start, geometry.Point{(start[0] + end[0]) / 2, (start[1] + end[1]) / 2})
+		}
+		ring = append(ring, ring[0])
+		g := geojson.NewGeometry(geometry.Polygon{ring})
+
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			cfg := &EncodingConfig{Dimension: 2, Precision: 1e6}
+			WithSimplification(1e-9)(cfg)
+			for i := 0; i < b.N; i++ {
+				if _, err := EncodeGeometry(g, cfg); err != nil {
+					b.Fatalf("Got unexpected error %s!", err)
+				}
+			}
+		})
+	}
+}
@@ -58,3 +58,44 @@ func (k *keyStore) sort() {
 		k.sorted = true
 	}
 }
+
+// orderedKeyStore keeps keys in insertion order, so an index never changes
+// once it has been handed out. This lets features be encoded before the full
+// set of keys is known.
+type orderedKeyStore struct {
+	keys    []string
+	indexes map[string]int
+}
+
+func NewOrderedKeyStore() *orderedKeyStore {
+	return &orderedKeyStore{
+		keys:    []string{},
+		indexes: make(map[string]int),
+	}
+}
+
+func (k *orderedKeyStore) Keys() []string {
+	return k.keys
+}
+
+// IndexOf returns -1 for keys that haven't been added.
+func (k *orderedKeyStore) IndexOf(key string) int {
+	if idx, ok := k.indexes[key]; ok {
+		return idx
+	}
+	return -1
+}
+
+func (k *orderedKeyStore) Add(key string) int {
+	if idx, ok := k.indexes[key]; ok {
+		return idx
+	}
+	k.keys = append(k.keys, key)
+	k.indexes[key] = len(k.keys) - 1
+	return len(k.keys) - 1
+}
+
+func (k *orderedKeyStore) Reset() {
+	k.keys = []string{}
+	k.indexes = make(map[string]int)
+}
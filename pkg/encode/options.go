@@ -1,6 +1,9 @@
 package encode
 
 import (
+	"errors"
+	"sort"
+
 	"github.com/cairnapp/go-geobuf/pkg/geojson"
 	"github.com/cairnapp/go-geobuf/pkg/geometry"
 	"github.com/cairnapp/go-geobuf/pkg/math"
@@ -10,6 +13,94 @@ type EncodingConfig struct {
 	Dimension uint
 	Precision uint
 	Keys      KeyStore
+	// SharedValues stores the values of all features in a collection once,
+	// in the collection's values, rather than in each feature.
+	SharedValues bool
+	// MaxPrecision caps the precision picked by analysis, 0 means no cap.
+	MaxPrecision uint
+	// DimensionPrecisions overrides Precision for individual dimensions,
+	// indexed by dimension. Zero entries fall back to Precision.
+	DimensionPrecisions []uint
+	// Validate rejects invalid geometries rather than encoding them.
+	Validate bool
+	// AutoClose closes unclosed rings before validating them.
+	AutoClose bool
+	// Rewind orients the rings of polygons before encoding them, the way
+	// RFC 7946 requires if RewindRFC7946 is set and the other way round
+	// otherwise.
+	Rewind        bool
+	RewindRFC7946 bool
+	// SimplifyTolerance simplifies lines and polygons before encoding them,
+	// 0 means they're encoded as they are.
+	SimplifyTolerance float64
+	// Transform maps every point before it's simplified and encoded, such as
+	// to reproject it. Nil leaves points as they are.
+	Transform func(geometry.Point) geometry.Point
+	// Affine quantizes the first two dimensions to the grid it maps to the
+	// world, writing whole grid coordinates. Nil keeps world coordinates.
+	Affine *geometry.Affine
+
+	// analyzed holds the precision analysis found for each dimension, so
+	// that options applied after FromAnalysis can still take effect.
+	analyzed []uint
+	// ceiling is the highest precision the largest analyzed coordinates
+	// can be scaled by without overflowing, 0 if there's no such limit.
+	ceiling uint
+}
+
+// Precisions returns the precision of each dimension.
+func (o *EncodingConfig) Precisions() []uint {
+	precisions := make([]uint, o.Dimension)
+	for i := range precisions {
+		precisions[i] = o.Precision
+		if e, ok := o.dimensionPrecision(i); ok {
+			precisions[i] = e
+		}
+	}
+	return precisions
+}
+
+// dimensionPrecision returns the precision a dimension has of its own, if
+// any. Dimensions quantized by Affine are whole grid coordinates.
+func (o *EncodingConfig) dimensionPrecision(dimension int) (uint, bool) {
+	if o.Affine != nil && dimension < 2 {
+		return 1, true
+	}
+	if dimension < len(o.DimensionPrecisions) && o.DimensionPrecisions[dimension] > 0 {
+		return o.DimensionPrecisions[dimension], true
+	}
+	return 0, false
+}
+
+func (o *EncodingConfig) hasDimensionPrecision(dimension int) bool {
+	_, ok := o.dimensionPrecision(dimension)
+	return ok
+}
+
+// reanalyze picks the shared precision again from what analysis found,
+// leaving out dimensions that have since been given their own.
+func (o *EncodingConfig) reanalyze() {
+	if len(o.analyzed) == 0 {
+		return
+	}
+	o.Precision = 1
+	for i, e := range o.analyzed {
+		if !o.hasDimensionPrecision(i) && e > o.Precision {
+			o.Precision = o.capPrecision(e)
+		}
+	}
+}
+
+// capPrecision limits e to MaxPrecision, and to what analysis found the
+// coordinates can take.
+func (o *EncodingConfig) capPrecision(e uint) uint {
+	if o.MaxPrecision > 0 && e > o.MaxPrecision {
+		e = o.MaxPrecision
+	}
+	if o.ceiling > 0 && e > o.ceiling {
+		e = o.ceiling
+	}
+	return e
 }
 
 type EncodingOption func(o *EncodingConfig)
@@ -20,6 +111,35 @@ func WithPrecision(precision uint) EncodingOption {
 	}
 }
 
+// WithMaxPrecision caps the precision picked by FromAnalysis to the given
+// number of digits, so that a few over-precise values don't inflate every
+// coordinate. It also lowers the precision analysis has already picked.
+func WithMaxPrecision(precision uint) EncodingOption {
+	return func(o *EncodingConfig) {
+		o.MaxPrecision = uint(math.DecodePrecision(uint32(precision)))
+		o.Precision = o.capPrecision(o.Precision)
+	}
+}
+
+// WithDimensionPrecision gives a single dimension, such as elevation at
+// index 2, its own precision in digits. Analysis then leaves that dimension
+// out of the shared precision.
+//
+// The precisions are stored in dimension_precisions, which other geobuf
+// implementations ignore: they'll still read the first two dimensions
+// correctly as long as those use the shared precision.
+func WithDimensionPrecision(dimension uint, precision uint) EncodingOption {
+	return func(o *EncodingConfig) {
+		for uint(len(o.DimensionPrecisions)) <= dimension {
+			o.DimensionPrecisions = append(o.DimensionPrecisions, 0)
+		}
+		o.DimensionPrecisions[dimension] = uint(math.DecodePrecision(uint32(precision)))
+
+		// Drop this dimension from a shared precision analysis already picked
+		o.reanalyze()
+	}
+}
+
 func WithDimension(dimension uint) EncodingOption {
 	return func(o *EncodingConfig) {
 		o.Dimension = dimension
@@ -32,69 +152,341 @@ func WithKeyStore(store KeyStore) EncodingOption {
 	}
 }
 
+// WithSharedValues deduplicates property values across a whole feature
+// collection. Features then reference the collection's values, which the
+// reference implementation doesn't understand, and which can't be streamed
+// since the values are only known once every feature has been encoded.
+// Encoders therefore ignore it and keep values per feature, and Decoders
+// reject collections marshaled with it.
+func WithSharedValues() EncodingOption {
+	return func(o *EncodingConfig) {
+		o.SharedValues = true
+	}
+}
+
+// WithValidation checks every geometry with validate.Geometry before
+// encoding it, and fails with a *validate.Error saying where a geometry is
+// invalid.
+func WithValidation() EncodingOption {
+	return func(o *EncodingConfig) {
+		o.Validate = true
+	}
+}
+
+// WithAutoClose closes rings that don't end where they start. Decoding
+// always closes rings, so on its own this doesn't change the output, but
+// it lets unclosed rings pass WithValidation.
+func WithAutoClose() EncodingOption {
+	return func(o *EncodingConfig) {
+		o.AutoClose = true
+	}
+}
+
+// WithRewind winds the exterior rings of polygons counter-clockwise and
+// their holes clockwise, as RFC 7946 requires, or the other way round if
+// rfc7946 is false.
+func WithRewind(rfc7946 bool) EncodingOption {
+	return func(o *EncodingConfig) {
+		o.Rewind = true
+		o.RewindRFC7946 = rfc7946
+	}
+}
+
+// WithSimplification simplifies lines and polygons with the
+// Douglas-Peucker algorithm before encoding them, dropping points that lie
+// within tolerance of the simplified geometry. Rings that would be left
+// with fewer than four points are kept as they are, and so are polygons
+// that simplifying would make invalid.
+func WithSimplification(tolerance float64) EncodingOption {
+	return func(o *EncodingConfig) {
+		o.SimplifyTolerance = tolerance
+	}
+}
+
+// WithTransform applies f to every point before encoding it, such as
+// geometry.WGS84ToWebMercator to reproject coordinates. Points are
+// transformed one at a time as they're written, without copying the input,
+// unless they have to be simplified, rewound or validated first. Either way
+// f is called once for each point encoded, and once more for each point
+// FromAnalysis looks at if it comes after WithTransform. Bounding boxes are
+// replaced by the bounds of their transformed corners, while properties are
+// written as they are. WithSimplification's tolerance is in the transformed
+// units.
+func WithTransform(f func(geometry.Point) geometry.Point) EncodingOption {
+	return func(o *EncodingConfig) {
+		o.Transform = f
+	}
+}
+
+// WithAffine writes the first two dimensions as whole coordinates on the
+// grid that affine maps to the world, after any WithTransform. The affine
+// transform is stored alongside the data, and decoding maps the grid
+// coordinates back to the world. Simplification and rewinding still work
+// in world coordinates.
+func WithAffine(affine geometry.Affine) EncodingOption {
+	return func(o *EncodingConfig) {
+		o.Affine = &affine
+		o.reanalyze()
+	}
+}
+
+// WithTile is WithAffine for the grid of the vector tile z/x/y, extent units
+// wide, which the points must be in Web Mercator metres for. Points outside
+// the tile are written outside the grid rather than clipped.
+func WithTile(z, x, y, extent uint32) EncodingOption {
+	return WithAffine(geometry.TileAffine(z, x, y, extent))
+}
+
 func FromAnalysis(obj interface{}) EncodingOption {
 	return func(o *EncodingConfig) {
-		analyze(obj, o)
+		if o.Dimension < 2 {
+			o.Dimension = 2
+		}
+		a := &analysis{opts: o, stride: 1}
+		a.analyze(obj)
 	}
 }
 
-func analyze(obj interface{}, opts *EncodingConfig) {
-	opts.Dimension = 2
+// FromSampledAnalysis is FromAnalysis for large objects. Keys and the
+// dimension still come from everything, but only n evenly spread points are
+// transformed and have their precision worked out, so points needing more
+// precision than all of the sampled ones will be rounded.
+//
+// Nor are the other points checked for overflowing at the precision picked.
+// EncodeWithOptions and MarshalWithOptions lower the precision and encode
+// again if one of them does, while an Encoder, which has already written
+// its precision, fails.
+func FromSampledAnalysis(obj interface{}, n int) EncodingOption {
+	return func(o *EncodingConfig) {
+		if o.Dimension < 2 {
+			o.Dimension = 2
+		}
+		a := &analysis{opts: o, stride: 1}
+		if total := countPoints(obj); n > 0 && total > n {
+			a.stride = (total + n - 1) / n
+		}
+		a.analyze(obj)
+	}
+}
+
+// LowerPrecision lowers the precision analysis picked so that the
+// coordinate err reports overflowing at it fits, and reports whether it
+// did. Precisions that weren't picked by analysis are left as they are.
+func (o *EncodingConfig) LowerPrecision(err error) bool {
+	var coordErr *CoordinateError
+	if o.analyzed == nil || !errors.As(err, &coordErr) || !errors.Is(coordErr.Err, ErrCoordinateOverflow) || coordErr.Precision != o.Precision {
+		return false
+	}
+	e := math.MaxPrecisionFor(coordErr.Value)
+	if e == 0 {
+		return false
+	}
+	if o.ceiling == 0 || e < o.ceiling {
+		o.ceiling = e
+	}
+	previous := o.Precision
+	o.Precision = o.capPrecision(o.Precision)
+	return o.Precision < previous
+}
+
+// countPoints returns how many points obj has, going by the lengths of its
+// coordinates rather than looking at every point.
+func countPoints(obj interface{}) int {
 	switch t := obj.(type) {
 	case *geojson.FeatureCollection:
+		n := 0
 		for _, feature := range t.Features {
-			analyze(feature, opts)
+			n += countPoints(feature)
 		}
+		return n
 	case *geojson.Feature:
-		analyze(geojson.NewGeometry(t.Geometry), opts)
-		for key, _ := range t.Properties {
-			opts.Keys.Add(key)
-		}
+		return countCoordinates(t.Geometry)
 	case *geojson.Geometry:
-		switch t.Type {
-		case GeometryPoint:
-			updatePrecision(t.Coordinates.(geometry.Point), opts)
-		case GeometryMultiPoint:
-			coords := t.Coordinates.(geometry.MultiPoint)
-			for _, coord := range coords {
-				updatePrecision(coord, opts)
+		if t.Type == geojson.GeometryCollectionType {
+			n := 0
+			for _, child := range t.Geometries {
+				n += countPoints(child)
 			}
-		case GeometryLineString:
-			coords := t.Coordinates.(geometry.LineString)
-			for _, coord := range coords {
-				updatePrecision(coord, opts)
+			return n
+		}
+		return countCoordinates(t.Coordinates)
+	}
+	return 0
+}
+
+func countCoordinates(g geometry.Geometry) int {
+	n := 0
+	switch t := g.(type) {
+	case geometry.Point:
+		n = 1
+	case geometry.MultiPoint:
+		n = len(t)
+	case geometry.LineString:
+		n = len(t)
+	case geometry.MultiLineString:
+		for _, line := range t {
+			n += len(line)
+		}
+	case geometry.Polygon:
+		for _, ring := range t {
+			n += len(ring)
+		}
+	case geometry.MultiPolygon:
+		for _, rings := range t {
+			for _, ring := range rings {
+				n += len(ring)
 			}
-		case GeometryMultiLineString:
-			lines := t.Coordinates.(geometry.MultiLineString)
-			for _, line := range lines {
-				for _, coord := range line {
-					updatePrecision(coord, opts)
-				}
+		}
+	case geometry.Collection:
+		for _, child := range t {
+			n += countCoordinates(child)
+		}
+	}
+	return n
+}
+
+// analysis walks an object, adding its keys to the key store and raising
+// the dimension and precision to what its points need.
+type analysis struct {
+	opts *EncodingConfig
+	// stride is how many points to move on before inspecting a point
+	// again, and skip how many are left to go. Points in between only count
+	// towards the dimension.
+	stride int
+	skip   int
+}
+
+func (a *analysis) analyze(obj interface{}) {
+	switch t := obj.(type) {
+	case *geojson.FeatureCollection:
+		for _, feature := range t.Features {
+			a.analyze(feature)
+		}
+		a.addKeys(withBBox(t.Custom, t.BBox))
+	case *geojson.Feature:
+		a.coordinates(t.Geometry)
+		a.addKeys(t.Properties)
+		a.addKeys(withBBox(t.Custom, t.BBox))
+		a.addKeys(withBBox(t.GeometryCustom, t.GeometryBBox))
+	case *geojson.Geometry:
+		a.addKeys(withBBox(t.Custom, t.BBox))
+		if t.Type == geojson.GeometryCollectionType {
+			for _, child := range t.Geometries {
+				a.analyze(child)
 			}
-		case GeometryPolygon:
-			lines := t.Coordinates.(geometry.Polygon)
-			for _, line := range lines {
-				for _, coord := range line {
-					updatePrecision(coord, opts)
-				}
+		} else {
+			a.coordinates(t.Coordinates)
+		}
+	}
+}
+
+func (a *analysis) coordinates(g geometry.Geometry) {
+	switch t := g.(type) {
+	case geometry.Point:
+		a.points([]geometry.Point{t})
+	case geometry.MultiPoint:
+		a.points(t)
+	case geometry.LineString:
+		a.points(t)
+	case geometry.MultiLineString:
+		for _, line := range t {
+			a.points(line)
+		}
+	case geometry.Polygon:
+		for _, ring := range t {
+			a.points(ring)
+		}
+	case geometry.MultiPolygon:
+		for _, rings := range t {
+			for _, ring := range rings {
+				a.points(ring)
 			}
-		case GeometryMultiPolygon:
-			polygons := t.Coordinates.(geometry.MultiPolygon)
-			for _, rings := range polygons {
-				for _, ring := range rings {
-					for _, coord := range ring {
-						updatePrecision(coord, opts)
-					}
-				}
+		}
+	case geometry.Collection:
+		for _, child := range t {
+			a.coordinates(child)
+		}
+	}
+}
+
+// addKeys adds any new keys in props. They're added in order so that
+// insertion ordered key stores are deterministic too.
+func (a *analysis) addKeys(props map[string]interface{}) {
+	if len(props) == 0 {
+		return
+	}
+
+	var missing []string
+	for key := range props {
+		if !hasKey(a.opts.Keys, key) {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		a.opts.Keys.Add(key)
+	}
+}
+
+// points inspects every stride-th point, and only checks the dimension of
+// the ones in between.
+func (a *analysis) points(points []geometry.Point) {
+	for _, point := range points {
+		if a.skip > 0 {
+			a.skip--
+			if uint(len(point)) > a.opts.Dimension {
+				a.opts.Dimension = uint(len(point))
 			}
+			continue
+		}
+		a.skip = a.stride - 1
+
+		if a.opts.Transform != nil {
+			point = a.opts.Transform(point)
+		}
+		if uint(len(point)) > a.opts.Dimension {
+			a.opts.Dimension = uint(len(point))
 		}
+		updateCeiling(point, a.opts)
+		updatePrecision(point, a.opts)
 	}
+}
 
+// updateCeiling lowers the config's precision if the point would overflow
+// at it.
+func updateCeiling(point geometry.Point, opt *EncodingConfig) {
+	for i, val := range point {
+		if opt.hasDimensionPrecision(i) {
+			continue
+		}
+		// Values that can't be scaled at all are left for encoding to reject
+		if e := math.MaxPrecisionFor(val); e > 0 && (opt.ceiling == 0 || e < opt.ceiling) {
+			opt.ceiling = e
+			opt.Precision = opt.capPrecision(opt.Precision)
+		}
+	}
 }
 
+// updatePrecision raises the config's precision so that it can represent
+// the given point. Dimensions that already need the most precision allowed
+// aren't looked at again.
 func updatePrecision(point geometry.Point, opt *EncodingConfig) {
-	for _, val := range point {
-		e := math.GetPrecision(val)
+	limit := opt.capPrecision(math.MaxPrecision)
+	for i, val := range point {
+		if i >= len(opt.analyzed) {
+			opt.analyzed = append(opt.analyzed, 1)
+		}
+		if opt.analyzed[i] >= limit || !math.CanScale(val, 1) {
+			continue
+		}
+		e := math.GetPrecisionUpTo(val, limit)
+		if e > opt.analyzed[i] {
+			opt.analyzed[i] = e
+		}
+
+		if opt.hasDimensionPrecision(i) {
+			continue
+		}
 		if e > opt.Precision {
 			opt.Precision = e
 		}
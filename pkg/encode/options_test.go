@@ -0,0 +1,130 @@
+package encode_test
+
+import (
+	gomath "math"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+// analysisFixture returns n points with 3 decimals, except for the second
+// one which is in 3D with 6 decimals and has an extra property.
+func analysisFixture(n int) *geojson.FeatureCollection {
+	collection := geojson.NewFeatureCollection()
+	for i := 0; i < n; i++ {
+		point := geometry.Point([]float64{float64(i) + 0.125, 0.5})
+		feature := geojson.NewFeature(point)
+		feature.Properties["name"] = "point"
+		if i == 1 {
+			feature.Geometry = geometry.Point([]float64{1.123456, 0.5, 10})
+			feature.Properties["extra"] = true
+		}
+		collection.Append(feature)
+	}
+	return collection
+}
+
+func analyzeWith(opt EncodingOption) *EncodingConfig {
+	cfg := &EncodingConfig{
+		Dimension: 2,
+		Precision: 1,
+		Keys:      NewKeyStore(),
+	}
+	opt(cfg)
+	return cfg
+}
+
+func TestFromSampledAnalysis(t *testing.T) {
+	collection := analysisFixture(1000)
+	testCases := []struct {
+		Option    EncodingOption
+		Precision uint
+	}{
+		{FromAnalysis(collection), 1e6},
+		{FromSampledAnalysis(collection, 0), 1e6},
+		{FromSampledAnalysis(collection, 2000), 1e6},
+		{FromSampledAnalysis(collection, 10), 1000},
+	}
+
+	for i, test := range testCases {
+		cfg := analyzeWith(test.Option)
+		if cfg.Precision != test.Precision {
+			t.Errorf("Case [%d]: Expected precision %d, got %d", i, test.Precision, cfg.Precision)
+		}
+		if cfg.Dimension != 3 {
+			t.Errorf("Case [%d]: Expected dimension 3, got %d", i, cfg.Dimension)
+		}
+		if keys := cfg.Keys.Keys(); !reflect.DeepEqual([]string{"extra", "name"}, keys) {
+			t.Errorf("Case [%d]: Expected keys [extra name], got %v", i, keys)
+		}
+	}
+}
+
+// parcelsFixture returns n polygons with 500 vertices each, at the sort of
+// precision left behind by reprojection.
+func parcelsFixture(n int) *geojson.FeatureCollection {
+	collection := geojson.NewFeatureCollection()
+	for i := 0; i < n; i++ {
+		ring := make(geometry.Ring, 500)
+		for j := range ring {
+			ring[j] = geometry.Point([]float64{174.7 + float64(j)/3000, -36.8 - float64(i)/7000})
+		}
+		ring[len(ring)-1] = ring[0]
+		feature := geojson.NewFeature(geometry.Polygon{ring})
+		feature.Properties["parcel"] = i
+		collection.Append(feature)
+	}
+	return collection
+}
+
+func TestFromAnalysisCoordinateLimits(t *testing.T) {
+	testCases := []struct {
+		Points    []geometry.Point
+		Precision uint
+	}{
+		// Projected coordinates with reprojection noise fit at 1e9
+		{[]geometry.Point{{20037508.342789244, -20037508.342789244}}, 1e9},
+		// A single large coordinate lowers the precision for all of them
+		{[]geometry.Point{{1.123456789, 2}, {4.7e12, 0}}, 1e5},
+		// Even when it comes first
+		{[]geometry.Point{{4.7e12, 0}, {1.123456789, 2}}, 1e5},
+		// Coordinates that can't be encoded at all are left for encoding
+		{[]geometry.Point{{gomath.NaN(), 1.5}}, 10},
+		{[]geometry.Point{{1 << 63, 1.5}}, 10},
+	}
+
+	for i, test := range testCases {
+		p := geojson.NewGeometry(geometry.MultiPoint(test.Points))
+		cfg := analyzeWith(FromAnalysis(p))
+		if cfg.Precision != test.Precision {
+			t.Errorf("Case [%d]: Expected precision %d, got %d", i, test.Precision, cfg.Precision)
+		}
+	}
+}
+
+func BenchmarkAnalysis(b *testing.B) {
+	collection := parcelsFixture(1000)
+	testCases := []struct {
+		name   string
+		option EncodingOption
+	}{
+		{"Full", FromAnalysis(collection)},
+		{"Sampled", FromSampledAnalysis(collection, 1000)},
+		{"Capped", func(o *EncodingConfig) {
+			WithMaxPrecision(2)(o)
+			FromAnalysis(collection)(o)
+		}},
+	}
+
+	for _, test := range testCases {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				analyzeWith(test.option)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package encode
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+)
+
+// encodeProperties adds the encoded values of props to values, returning the
+// pairs of key/value indexes that reference them. Properties are encoded in
+// key index order so that equal inputs always produce the same bytes.
+func encodeProperties(props map[string]interface{}, opts *EncodingConfig, values *valueStore) ([]uint32, error) {
+	keys := opts.Keys.Keys()
+	indexes := make(map[string]int, len(props))
+	ordered := make([]string, 0, len(props))
+	for key := range props {
+		idx := opts.Keys.IndexOf(key)
+		if idx < 0 || idx >= len(keys) || keys[idx] != key {
+			return nil, fmt.Errorf("Key %q is missing from the key store", key)
+		}
+		indexes[key] = idx
+		ordered = append(ordered, key)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return indexes[ordered[i]] < indexes[ordered[j]]
+	})
+
+	pairs := make([]uint32, 0, 2*len(props))
+	for _, key := range ordered {
+		encoded, err := EncodeValue(props[key])
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, uint32(indexes[key]))
+		pairs = append(pairs, uint32(values.Add(encoded)))
+	}
+	return pairs, nil
+}
+
+// withBBox returns custom with a non-empty bbox added, leaving custom itself
+// untouched. Bounding boxes are stored as custom properties, where other
+// geobuf implementations keep them as they do any foreign member.
+func withBBox(custom map[string]interface{}, bbox geojson.BBox) map[string]interface{} {
+	if len(bbox) == 0 {
+		return custom
+	}
+	merged := make(map[string]interface{}, len(custom)+1)
+	for key, val := range custom {
+		merged[key] = val
+	}
+	merged[geojson.BBoxMember] = []float64(bbox)
+	return merged
+}
+
+// hasKey checks whether key has been added to store.
+func hasKey(store KeyStore, key string) bool {
+	idx := store.IndexOf(key)
+	keys := store.Keys()
+	return idx >= 0 && idx < len(keys) && keys[idx] == key
+}
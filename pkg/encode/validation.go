@@ -0,0 +1,68 @@
+package encode
+
+import (
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+	"github.com/cairnapp/go-geobuf/pkg/validate"
+)
+
+func validateGeometry(g geometry.Geometry, opt *EncodingConfig) error {
+	if opt.AutoClose {
+		g = closeRings(g)
+	}
+	return validate.Geometry(g)
+}
+
+// closeRings returns g with all of its rings closed.
+func closeRings(g geometry.Geometry) geometry.Geometry {
+	switch t := g.(type) {
+	case geometry.Ring:
+		return t.Close()
+	case geometry.Polygon:
+		return closePolygon(t)
+	case geometry.MultiPolygon:
+		closed := make(geometry.MultiPolygon, len(t))
+		for i, polygon := range t {
+			closed[i] = closePolygon(polygon)
+		}
+		return closed
+	case geometry.Collection:
+		closed := make(geometry.Collection, len(t))
+		for i, child := range t {
+			closed[i] = closeRings(child)
+		}
+		return closed
+	}
+	return g
+}
+
+func closePolygon(polygon geometry.Polygon) geometry.Polygon {
+	closed := make(geometry.Polygon, len(polygon))
+	for i, ring := range polygon {
+		closed[i] = ring.Close()
+	}
+	return closed
+}
+
+// simplifyPolygon simplifies the polygon, unless that would make it
+// invalid, such as by leaving a hole outside of the exterior ring or making
+// a ring cross itself. The polygon is then kept as it is. Simplifying only
+// drops points, so only the rings that lost some are checked again, along
+// with every hole if the exterior ring did.
+func simplifyPolygon(polygon geometry.Polygon, tolerance float64) geometry.Polygon {
+	simplified := polygon.Simplify(tolerance)
+	if len(simplified) == 0 {
+		return simplified
+	}
+	exterior := simplified[0].Close()
+	exteriorChanged := len(simplified[0]) != len(polygon[0])
+	for i, ring := range simplified {
+		changed := len(ring) != len(polygon[i])
+		if changed && validate.Geometry(ring.Close()) != nil {
+			return polygon
+		}
+		if i > 0 && (changed || exteriorChanged) && validate.Hole(exterior, ring.Close()) != nil {
+			return polygon
+		}
+	}
+	return simplified
+}
@@ -0,0 +1,47 @@
+package encode
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+// valueStore holds the unique values of a feature, or of a whole collection
+// when values are shared, handing out the same index for equal values.
+type valueStore struct {
+	values  []*proto.Data_Value
+	indexes map[interface{}]int
+}
+
+// doubleBits keys doubles by their representation rather than their value.
+type doubleBits uint64
+
+func newValueStore() *valueStore {
+	return &valueStore{
+		indexes: make(map[interface{}]int),
+	}
+}
+
+func (v *valueStore) Values() []*proto.Data_Value {
+	return v.values
+}
+
+// Add returns the index of val, appending it if no equal value was added
+// before.
+func (v *valueStore) Add(val *proto.Data_Value) int {
+	// The oneof wrappers only hold a single scalar, so the structs they
+	// point to can be compared directly. They also keep e.g. the string "1"
+	// apart from the JSON value "1".
+	key := reflect.ValueOf(val.ValueType).Elem().Interface()
+	if d, ok := val.ValueType.(*proto.Data_Value_DoubleValue); ok {
+		// Except doubles, which would take -0 for 0 and never find a NaN
+		key = doubleBits(math.Float64bits(d.DoubleValue))
+	}
+	if idx, ok := v.indexes[key]; ok {
+		return idx
+	}
+	v.values = append(v.values, val)
+	v.indexes[key] = len(v.values) - 1
+	return len(v.values) - 1
+}
@@ -30,6 +30,9 @@ func encodeValue(v reflect.Value, val interface{}) (*proto.Data_Value, error) {
 		return encodeString(v.String())
 	case reflect.Ptr:
 		return encodeValue(v.Elem(), val)
+	case reflect.Invalid:
+		// Nil values and nil pointers, such as JSON nulls
+		return encodeJSON(nil)
 	default:
 		return encodeJSON(v.Interface())
 	}
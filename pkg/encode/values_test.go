@@ -258,6 +258,14 @@ func TestEncodeJsonValue(t *testing.T) {
 			Val:      map[string]int{"1": 1},
 			Expected: "{\"1\":1}",
 		},
+		{
+			Val:      nil,
+			Expected: "null",
+		},
+		{
+			Val:      (*string)(nil),
+			Expected: "null",
+		},
 	}
 
 	for i, test := range testCases {
@@ -0,0 +1,108 @@
+package geojson
+
+import (
+	"fmt"
+
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+// BBoxMember is the name of the member holding an object's bounding box.
+const BBoxMember = "bbox"
+
+// BBox is a GeoJSON bounding box, holding the minimum of every dimension
+// followed by the maximum of every dimension.
+type BBox []float64
+
+// NewBBox returns the bounding box of a bound, or nil for an empty bound.
+func NewBBox(b geometry.Bound) BBox {
+	if b.IsEmpty() {
+		return nil
+	}
+	bbox := make(BBox, 0, 2*len(b.Min))
+	bbox = append(bbox, b.Min...)
+	return append(bbox, b.Max...)
+}
+
+// Bound returns the bound the bounding box describes.
+func (b BBox) Bound() geometry.Bound {
+	n := len(b) / 2
+	if n == 0 {
+		return geometry.Bound{}
+	}
+	return geometry.Bound{
+		Min: geometry.Point(append([]float64{}, b[:n]...)),
+		Max: geometry.Point(append([]float64{}, b[n:2*n]...)),
+	}
+}
+
+// Transform returns the bounding box of the bbox's corners once f has
+// transformed them, which still covers the transformed geometries as long
+// as f keeps each axis in order, as reprojections do. A nil f, or a
+// malformed bbox, returns the bbox as it is.
+func (b BBox) Transform(f func(geometry.Point) geometry.Point) BBox {
+	if f == nil || b.validate() != nil || len(b) == 0 {
+		return b
+	}
+	bound := b.Bound()
+	var transformed geometry.Bound
+	for corner := 0; corner < 1<<len(bound.Min); corner++ {
+		point := make(geometry.Point, len(bound.Min))
+		for i := range point {
+			point[i] = bound.Min[i]
+			if corner&(1<<i) != 0 {
+				point[i] = bound.Max[i]
+			}
+		}
+		transformed.Extend(f(point))
+	}
+	return NewBBox(transformed)
+}
+
+func (b BBox) validate() error {
+	if len(b) > 0 && (len(b)%2 != 0 || len(b) < 4) {
+		return fmt.Errorf("geojson: bbox needs 2 values per dimension, got %d", len(b))
+	}
+	return nil
+}
+
+// ComputeBBox sets the bounding box of the geometry, and of its children
+// for collections.
+func (g *Geometry) ComputeBBox() {
+	for _, child := range g.Geometries {
+		child.ComputeBBox()
+	}
+	g.BBox = NewBBox(g.bound())
+}
+
+func (g *Geometry) bound() geometry.Bound {
+	if g.Type != GeometryCollectionType {
+		if g.Coordinates == nil {
+			return geometry.Bound{}
+		}
+		return g.Coordinates.Bound()
+	}
+	b := geometry.Bound{}
+	for _, child := range g.Geometries {
+		b.Union(child.bound())
+	}
+	return b
+}
+
+// ComputeBBox sets the bounding box of the feature from its geometry.
+func (f *Feature) ComputeBBox() {
+	f.BBox = nil
+	if f.Geometry != nil {
+		f.BBox = NewBBox(f.Geometry.Bound())
+	}
+}
+
+// ComputeBBox sets the bounding box of every feature, and of the collection
+// as a whole.
+func (fc *FeatureCollection) ComputeBBox() {
+	b := geometry.Bound{}
+	for _, feature := range fc.Features {
+		feature.ComputeBBox()
+		b.Union(feature.BBox.Bound())
+	}
+	fc.BBox = NewBBox(b)
+}
@@ -0,0 +1,168 @@
+package geojson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestGeometryComputeBBox(t *testing.T) {
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Expected BBox
+	}{
+		{
+			Geometry: geometry.Point([]float64{1, 2}),
+			Expected: BBox{1, 2, 1, 2},
+		},
+		{
+			Geometry: geometry.LineString([]geometry.Point{
+				geometry.Point([]float64{3, -4}),
+				geometry.Point([]float64{-1, 2}),
+			}),
+			Expected: BBox{-1, -4, 3, 2},
+		},
+		{
+			Geometry: geometry.MultiPoint([]geometry.Point{
+				geometry.Point([]float64{1, 2}),
+				geometry.Point([]float64{3, 4, 5}),
+			}),
+			Expected: BBox{1, 2, 5, 3, 4, 5},
+		},
+		{
+			Geometry: geometry.Polygon([]geometry.Ring{
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{0, 0}),
+					geometry.Point([]float64{4, 0}),
+					geometry.Point([]float64{4, 4}),
+					geometry.Point([]float64{0, 0}),
+				}),
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{1, 1, 10}),
+					geometry.Point([]float64{2, 1, 20}),
+					geometry.Point([]float64{2, 2, 30}),
+					geometry.Point([]float64{1, 1, 10}),
+				}),
+			}),
+			Expected: BBox{0, 0, 10, 4, 4, 30},
+		},
+		{
+			Geometry: geometry.MultiPolygon{},
+			Expected: nil,
+		},
+		{
+			Geometry: geometry.Collection{
+				geometry.Point([]float64{5, 5}),
+				geometry.Collection{},
+				geometry.LineString([]geometry.Point{
+					geometry.Point([]float64{-1, 0}),
+					geometry.Point([]float64{0, 1}),
+				}),
+			},
+			Expected: BBox{-1, 0, 5, 5},
+		},
+	}
+
+	for i, test := range testCases {
+		g := NewGeometry(test.Geometry)
+		g.ComputeBBox()
+		if !reflect.DeepEqual(test.Expected, g.BBox) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, g.BBox)
+		}
+	}
+}
+
+func TestGeometryComputeBBoxChildren(t *testing.T) {
+	g := NewGeometry(geometry.Collection{
+		geometry.Point([]float64{1, 2}),
+		geometry.Point([]float64{3, 4}),
+	})
+	g.ComputeBBox()
+
+	expected := []BBox{{1, 2, 1, 2}, {3, 4, 3, 4}}
+	for i, child := range g.Geometries {
+		if !reflect.DeepEqual(expected[i], child.BBox) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, expected[i], child.BBox)
+		}
+	}
+}
+
+func TestFeatureCollectionComputeBBox(t *testing.T) {
+	collection := NewFeatureCollection()
+	collection.Append(NewFeature(geometry.Point([]float64{1, 2})))
+	collection.Append(NewFeature(nil))
+	collection.Append(NewFeature(geometry.Point([]float64{-3, 4})))
+	collection.ComputeBBox()
+
+	expected := []BBox{{1, 2, 1, 2}, nil, {-3, 4, -3, 4}}
+	for i, feature := range collection.Features {
+		if !reflect.DeepEqual(expected[i], feature.BBox) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, expected[i], feature.BBox)
+		}
+	}
+	if expected := (BBox{-3, 2, 1, 4}); !reflect.DeepEqual(expected, collection.BBox) {
+		t.Errorf("Expected %v, got %v", expected, collection.BBox)
+	}
+}
+
+func TestBBoxJSON(t *testing.T) {
+	source := `{"type":"FeatureCollection","bbox":[-3,2,1,4],"features":[{"type":"Feature","bbox":[1,2,1,2],"geometry":{"type":"Point","coordinates":[1,2]},"properties":{}}]}`
+
+	decoded := &FeatureCollection{}
+	if err := json.Unmarshal([]byte(source), decoded); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if expected := (BBox{-3, 2, 1, 4}); !reflect.DeepEqual(expected, decoded.BBox) {
+		t.Errorf("Expected %v, got %v", expected, decoded.BBox)
+	}
+	if decoded.Custom != nil {
+		t.Errorf("Expected no custom members, got %v", decoded.Custom)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if string(encoded) != source {
+		t.Errorf("Expected %s, got %s", source, encoded)
+	}
+}
+
+func TestBBoxJSONInvalid(t *testing.T) {
+	testCases := []string{
+		`{"type":"Point","bbox":[1,2],"coordinates":[1,2]}`,
+		`{"type":"Point","bbox":[1,2,3,4,5],"coordinates":[1,2]}`,
+		`{"type":"Point","bbox":"1,2,3,4","coordinates":[1,2]}`,
+	}
+
+	for i, test := range testCases {
+		decoded := &Geometry{}
+		if err := json.Unmarshal([]byte(test), decoded); err == nil {
+			t.Errorf("Case [%d]: Expected an error, got %+v", i, decoded)
+		}
+	}
+}
+
+func TestBBoxTransform(t *testing.T) {
+	flip := func(p geometry.Point) geometry.Point {
+		return geometry.Point{-p[0], p[1] * 2}
+	}
+	testCases := []struct {
+		BBox     BBox
+		Expected BBox
+	}{
+		{BBox: BBox{1, 2, 3, 4}, Expected: BBox{-3, 4, -1, 8}},
+		{BBox: nil, Expected: nil},
+		// Malformed bboxes are left alone
+		{BBox: BBox{1, 2, 3}, Expected: BBox{1, 2, 3}},
+	}
+
+	for i, test := range testCases {
+		if got := test.BBox.Transform(flip); !reflect.DeepEqual(test.Expected, got) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// appendMembers adds the custom members to an already marshaled JSON object.
+// Members that clash with the object's own reserved members are skipped.
+func appendMembers(obj []byte, custom Properties, reserved ...string) ([]byte, error) {
+	keys := make([]string, 0, len(custom))
+	for key := range custom {
+		if !isReserved(key, reserved) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return obj, nil
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(obj[:len(obj)-1])
+	for _, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(custom[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// extractMembers parses every member of a JSON object that isn't reserved,
+// returning nil if there are none.
+func extractMembers(data []byte, reserved ...string) (Properties, error) {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	var custom Properties
+	for key, raw := range members {
+		if isReserved(key, reserved) {
+			continue
+		}
+		var val interface{}
+		if err := json.Unmarshal(raw, &val); err != nil {
+			return nil, err
+		}
+		if custom == nil {
+			custom = make(Properties)
+		}
+		custom[key] = val
+	}
+	return custom, nil
+}
+
+func isReserved(key string, reserved []string) bool {
+	for _, r := range reserved {
+		if key == r {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,13 @@
 package geojson
 
-import "github.com/cairnapp/go-geobuf/pkg/geometry"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
 
 type Properties map[string]interface{}
 
@@ -12,8 +19,20 @@ type Feature struct {
 	Type       string            `json:"type"`
 	Geometry   geometry.Geometry `json:"geometry"`
 	Properties Properties        `json:"properties"`
+	BBox       BBox              `json:"bbox,omitempty"`
+
+	// Custom holds foreign members of the feature object, such as "title".
+	Custom Properties `json:"-"`
+	// GeometryBBox and GeometryCustom hold the bbox and foreign members,
+	// such as "crs", of the feature's geometry object, since Geometry only
+	// holds coordinates. Those of geometries inside a GeometryCollection
+	// aren't kept.
+	GeometryBBox   BBox       `json:"-"`
+	GeometryCustom Properties `json:"-"`
 }
 
+var featureMembers = []string{"id", "type", BBoxMember, "geometry", "properties"}
+
 func NewFeature(geometry geometry.Geometry) *Feature {
 	return &Feature{
 		Type:       FeatureType,
@@ -21,3 +40,94 @@ func NewFeature(geometry geometry.Geometry) *Feature {
 		Properties: make(map[string]interface{}),
 	}
 }
+
+// GeometryObject returns the feature's geometry as a geometry object, with
+// its bbox and foreign members, or nil if the feature has no geometry.
+func (f *Feature) GeometryObject() *Geometry {
+	if f.Geometry == nil {
+		return nil
+	}
+	geo := NewGeometry(f.Geometry)
+	geo.BBox = f.GeometryBBox
+	geo.Custom = f.GeometryCustom
+	return geo
+}
+
+type jsonFeature struct {
+	ID         json.RawMessage `json:"id,omitempty"`
+	Type       string          `json:"type"`
+	BBox       BBox            `json:"bbox,omitempty"`
+	Geometry   *Geometry       `json:"geometry"`
+	Properties Properties      `json:"properties"`
+}
+
+// MarshalJSON encodes the feature as an RFC 7946 feature object.
+func (f Feature) MarshalJSON() ([]byte, error) {
+	obj, err := json.Marshal(struct {
+		ID         interface{} `json:"id,omitempty"`
+		Type       string      `json:"type"`
+		BBox       BBox        `json:"bbox,omitempty"`
+		Geometry   *Geometry   `json:"geometry"`
+		Properties Properties  `json:"properties"`
+	}{f.ID, FeatureType, f.BBox, f.GeometryObject(), f.Properties})
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(obj, f.Custom, featureMembers...)
+}
+
+// UnmarshalJSON parses an RFC 7946 feature object. Integral ids become
+// int64s, so they encode as geobuf int ids.
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	raw := jsonFeature{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Type != FeatureType {
+		return fmt.Errorf("geojson: expected type %q, got %q", FeatureType, raw.Type)
+	}
+	if err := raw.BBox.validate(); err != nil {
+		return err
+	}
+
+	id, err := unmarshalID(raw.ID)
+	if err != nil {
+		return err
+	}
+	custom, err := extractMembers(data, featureMembers...)
+	if err != nil {
+		return err
+	}
+
+	feature := NewFeature(nil)
+	feature.ID = id
+	feature.BBox = raw.BBox
+	feature.Custom = custom
+	if raw.Geometry != nil {
+		feature.Geometry = raw.Geometry.Geometry()
+		feature.GeometryBBox = raw.Geometry.BBox
+		feature.GeometryCustom = raw.Geometry.Custom
+	}
+	for key, val := range raw.Properties {
+		feature.Properties[key] = val
+	}
+	*f = *feature
+	return nil
+}
+
+func unmarshalID(data json.RawMessage) (interface{}, error) {
+	if data == nil || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var id interface{}
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, err
+	}
+	if _, ok := id.(float64); ok {
+		if intId, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+			return intId, nil
+		}
+	}
+	return id, nil
+}
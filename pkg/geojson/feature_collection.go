@@ -1,12 +1,23 @@
 package geojson
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type FeatureCollection struct {
 	Type     string     `json:"type"`
 	Features []*Feature `json:"features"`
+	BBox     BBox       `json:"bbox,omitempty"`
+
+	// Custom holds foreign members of the collection object, such as "crs"
+	Custom Properties `json:"-"`
 }
 
 const FeatureCollectionType = "FeatureCollection"
 
+var featureCollectionMembers = []string{"type", BBoxMember, "features"}
+
 func NewFeatureCollection() *FeatureCollection {
 	return &FeatureCollection{
 		Type:     FeatureCollectionType,
@@ -19,3 +30,50 @@ func (fc *FeatureCollection) Append(feature *Feature) *FeatureCollection {
 	fc.Features = append(fc.Features, feature)
 	return fc
 }
+
+type jsonFeatureCollection struct {
+	Type     string     `json:"type"`
+	BBox     BBox       `json:"bbox,omitempty"`
+	Features []*Feature `json:"features"`
+}
+
+// MarshalJSON encodes the collection as an RFC 7946 feature collection object.
+func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
+	features := fc.Features
+	if features == nil {
+		features = []*Feature{}
+	}
+	obj, err := json.Marshal(jsonFeatureCollection{FeatureCollectionType, fc.BBox, features})
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(obj, fc.Custom, featureCollectionMembers...)
+}
+
+// UnmarshalJSON parses an RFC 7946 feature collection object.
+func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
+	raw := jsonFeatureCollection{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Type != FeatureCollectionType {
+		return fmt.Errorf("geojson: expected type %q, got %q", FeatureCollectionType, raw.Type)
+	}
+	if err := raw.BBox.validate(); err != nil {
+		return err
+	}
+
+	custom, err := extractMembers(data, featureCollectionMembers...)
+	if err != nil {
+		return err
+	}
+
+	collection := NewFeatureCollection()
+	collection.BBox = raw.BBox
+	collection.Custom = custom
+	for _, feature := range raw.Features {
+		collection.Append(feature)
+	}
+	*fc = *collection
+	return nil
+}
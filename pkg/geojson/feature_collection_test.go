@@ -0,0 +1,79 @@
+package geojson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestFeatureCollectionJSON(t *testing.T) {
+	feature := NewFeature(geometry.Point([]float64{1, 2}))
+	feature.ID = int64(1)
+	collection := NewFeatureCollection()
+	collection.Append(feature)
+
+	testCases := []struct {
+		Collection *FeatureCollection
+		JSON       string
+	}{
+		{
+			Collection: NewFeatureCollection(),
+			JSON:       `{"type":"FeatureCollection","features":[]}`,
+		},
+		{
+			Collection: collection,
+			JSON:       `{"type":"FeatureCollection","features":[{"id":1,"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{}}]}`,
+		},
+	}
+
+	for i, test := range testCases {
+		encoded, err := json.Marshal(test.Collection)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if string(encoded) != test.JSON {
+			t.Errorf("Case [%d]: Expected %s, got %s", i, test.JSON, encoded)
+		}
+
+		decoded := &FeatureCollection{}
+		if err := json.Unmarshal([]byte(test.JSON), decoded); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Collection, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test.Collection, decoded)
+		}
+	}
+}
+
+func TestFeatureCollectionJSONInvalid(t *testing.T) {
+	decoded := &FeatureCollection{}
+	if err := json.Unmarshal([]byte(`{"type":"Feature","features":[]}`), decoded); err == nil {
+		t.Errorf("Expected an error, got %+v", decoded)
+	}
+}
+
+func TestFeatureCollectionJSONCustom(t *testing.T) {
+	source := `{"type":"FeatureCollection","features":[],"name":"parcels","type_version":2}`
+
+	decoded := &FeatureCollection{}
+	if err := json.Unmarshal([]byte(source), decoded); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	expected := NewFeatureCollection()
+	expected.Custom = Properties{"name": "parcels", "type_version": float64(2)}
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if string(encoded) != source {
+		t.Errorf("Expected %s, got %s", source, encoded)
+	}
+}
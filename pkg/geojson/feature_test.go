@@ -0,0 +1,112 @@
+package geojson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestFeatureJSON(t *testing.T) {
+	intFeature := NewFeature(geometry.Point([]float64{1, 2}))
+	intFeature.ID = int64(12)
+	intFeature.Properties["name"] = "a"
+	intFeature.Properties["size"] = float64(1.5)
+	intFeature.Properties["tags"] = []interface{}{"x", "y"}
+
+	stringFeature := NewFeature(geometry.Collection{geometry.Point([]float64{1, 2})})
+	stringFeature.ID = "abc"
+
+	floatFeature := NewFeature(nil)
+	floatFeature.ID = float64(1.5)
+
+	testCases := []struct {
+		Feature *Feature
+		JSON    string
+	}{
+		{
+			Feature: intFeature,
+			JSON:    `{"id":12,"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a","size":1.5,"tags":["x","y"]}}`,
+		},
+		{
+			Feature: stringFeature,
+			JSON:    `{"id":"abc","type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]},"properties":{}}`,
+		},
+		{
+			Feature: floatFeature,
+			JSON:    `{"id":1.5,"type":"Feature","geometry":null,"properties":{}}`,
+		},
+	}
+
+	for i, test := range testCases {
+		encoded, err := json.Marshal(test.Feature)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if string(encoded) != test.JSON {
+			t.Errorf("Case [%d]: Expected %s, got %s", i, test.JSON, encoded)
+		}
+
+		decoded := &Feature{}
+		if err := json.Unmarshal([]byte(test.JSON), decoded); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Feature, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test.Feature, decoded)
+		}
+	}
+}
+
+func TestFeatureJSONNullProperties(t *testing.T) {
+	decoded := &Feature{}
+	err := json.Unmarshal([]byte(`{"type":"Feature","geometry":null,"properties":null}`), decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	expected := NewFeature(nil)
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+}
+
+func TestFeatureJSONInvalid(t *testing.T) {
+	decoded := &Feature{}
+	if err := json.Unmarshal([]byte(`{"type":"Point","coordinates":[1,2]}`), decoded); err == nil {
+		t.Errorf("Expected an error, got %+v", decoded)
+	}
+}
+
+func TestFeatureJSONCustom(t *testing.T) {
+	source := `{"id":"abc","type":"Feature","geometry":{"type":"Point","coordinates":[1,2],"bbox":[0,0,3,3],"crs":"local"},"properties":{},"bbox":[1,2,1,2],"title":"A"}`
+
+	decoded := &Feature{}
+	if err := json.Unmarshal([]byte(source), decoded); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	expected := NewFeature(geometry.Point([]float64{1, 2}))
+	expected.ID = "abc"
+	expected.BBox = BBox{1, 2, 1, 2}
+	expected.Custom = Properties{
+		"title": "A",
+	}
+	expected.GeometryBBox = BBox{0, 0, 3, 3}
+	expected.GeometryCustom = Properties{
+		"crs": "local",
+	}
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	output := `{"id":"abc","type":"Feature","bbox":[1,2,1,2],"geometry":{"type":"Point","bbox":[0,0,3,3],"coordinates":[1,2],"crs":"local"},"properties":{},"title":"A"}`
+	if string(encoded) != output {
+		t.Errorf("Expected %s, got %s", output, encoded)
+	}
+}
@@ -1,6 +1,9 @@
 package geojson
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/cairnapp/go-geobuf/pkg/geometry"
 )
 
@@ -11,15 +14,21 @@ const (
 	GeometryMultiLineStringType = "MultiLineString"
 	GeometryPolygonType         = "Polygon"
 	GeometryMultiPolygonType    = "MultiPolygon"
-	GeometryCollectionType      = "GeometryCollectionType"
+	GeometryCollectionType      = "GeometryCollection"
 )
 
 type Geometry struct {
 	Type        string            `json:"type"`
 	Coordinates geometry.Geometry `json:"coordinates,omitempty"`
 	Geometries  []*Geometry       `json:"geometries,omitempty"`
+	BBox        BBox              `json:"bbox,omitempty"`
+
+	// Custom holds foreign members of the geometry object, such as "crs"
+	Custom Properties `json:"-"`
 }
 
+var geometryMembers = []string{"type", BBoxMember, "coordinates", "geometries"}
+
 func NewGeometry(g geometry.Geometry) *Geometry {
 	geo := &Geometry{}
 	switch typed := g.(type) {
@@ -50,3 +59,119 @@ func NewGeometry(g geometry.Geometry) *Geometry {
 	}
 	return geo
 }
+
+// Geometry returns the coordinates this object wraps, rebuilding a
+// geometry.Collection from its children for collections. Custom members
+// have nowhere to go and are dropped.
+func (g *Geometry) Geometry() geometry.Geometry {
+	if g.Type != GeometryCollectionType {
+		return g.Coordinates
+	}
+	collection := make(geometry.Collection, len(g.Geometries))
+	for i, child := range g.Geometries {
+		collection[i] = child.Geometry()
+	}
+	return collection
+}
+
+type jsonGeometry struct {
+	Type        string          `json:"type"`
+	BBox        BBox            `json:"bbox,omitempty"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []*Geometry     `json:"geometries,omitempty"`
+}
+
+// MarshalJSON encodes the geometry as an RFC 7946 geometry object.
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	var (
+		obj []byte
+		err error
+	)
+	if g.Type == GeometryCollectionType {
+		geometries := g.Geometries
+		if geometries == nil {
+			geometries = []*Geometry{}
+		}
+		obj, err = json.Marshal(struct {
+			Type       string      `json:"type"`
+			BBox       BBox        `json:"bbox,omitempty"`
+			Geometries []*Geometry `json:"geometries"`
+		}{g.Type, g.BBox, geometries})
+	} else {
+		obj, err = json.Marshal(struct {
+			Type        string            `json:"type"`
+			BBox        BBox              `json:"bbox,omitempty"`
+			Coordinates geometry.Geometry `json:"coordinates"`
+		}{g.Type, g.BBox, g.Coordinates})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(obj, g.Custom, geometryMembers...)
+}
+
+// UnmarshalJSON parses an RFC 7946 geometry object.
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	raw := jsonGeometry{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := raw.BBox.validate(); err != nil {
+		return err
+	}
+
+	custom, err := extractMembers(data, geometryMembers...)
+	if err != nil {
+		return err
+	}
+
+	if raw.Type == GeometryCollectionType {
+		geometries := raw.Geometries
+		if geometries == nil {
+			geometries = []*Geometry{}
+		}
+		*g = Geometry{Type: raw.Type, Geometries: geometries, BBox: raw.BBox, Custom: custom}
+		return nil
+	}
+
+	coords, err := unmarshalCoordinates(raw.Type, raw.Coordinates)
+	if err != nil {
+		return err
+	}
+	*g = Geometry{Type: raw.Type, Coordinates: coords, BBox: raw.BBox, Custom: custom}
+	return nil
+}
+
+func unmarshalCoordinates(geoType string, data json.RawMessage) (geometry.Geometry, error) {
+	if data == nil {
+		return nil, fmt.Errorf("geojson: %s is missing coordinates", geoType)
+	}
+
+	switch geoType {
+	case GeometryPointType:
+		coords := geometry.Point{}
+		err := json.Unmarshal(data, &coords)
+		return coords, err
+	case GeometryMultiPointType:
+		coords := geometry.MultiPoint{}
+		err := json.Unmarshal(data, &coords)
+		return coords, err
+	case GeometryLineStringType:
+		coords := geometry.LineString{}
+		err := json.Unmarshal(data, &coords)
+		return coords, err
+	case GeometryMultiLineStringType:
+		coords := geometry.MultiLineString{}
+		err := json.Unmarshal(data, &coords)
+		return coords, err
+	case GeometryPolygonType:
+		coords := geometry.Polygon{}
+		err := json.Unmarshal(data, &coords)
+		return coords, err
+	case GeometryMultiPolygonType:
+		coords := geometry.MultiPolygon{}
+		err := json.Unmarshal(data, &coords)
+		return coords, err
+	}
+	return nil, fmt.Errorf("geojson: unknown geometry type %q", geoType)
+}
@@ -0,0 +1,137 @@
+package geojson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestGeometryJSON(t *testing.T) {
+	testCases := []struct {
+		Geometry *Geometry
+		JSON     string
+	}{
+		{
+			Geometry: NewGeometry(geometry.Point([]float64{124.123, 234.456})),
+			JSON:     `{"type":"Point","coordinates":[124.123,234.456]}`,
+		},
+		{
+			Geometry: NewGeometry(geometry.MultiPoint([]geometry.Point{
+				geometry.Point([]float64{1, 2}),
+				geometry.Point([]float64{3, 4, 5}),
+			})),
+			JSON: `{"type":"MultiPoint","coordinates":[[1,2],[3,4,5]]}`,
+		},
+		{
+			Geometry: NewGeometry(geometry.LineString([]geometry.Point{
+				geometry.Point([]float64{1, 2}),
+				geometry.Point([]float64{3, 4}),
+			})),
+			JSON: `{"type":"LineString","coordinates":[[1,2],[3,4]]}`,
+		},
+		{
+			Geometry: NewGeometry(geometry.MultiLineString([]geometry.LineString{
+				geometry.LineString([]geometry.Point{
+					geometry.Point([]float64{1, 2}),
+					geometry.Point([]float64{3, 4}),
+				}),
+			})),
+			JSON: `{"type":"MultiLineString","coordinates":[[[1,2],[3,4]]]}`,
+		},
+		{
+			Geometry: NewGeometry(geometry.Polygon([]geometry.Ring{
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{0, 0}),
+					geometry.Point([]float64{1, 0}),
+					geometry.Point([]float64{1, 1}),
+					geometry.Point([]float64{0, 0}),
+				}),
+			})),
+			JSON: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		},
+		{
+			Geometry: NewGeometry(geometry.MultiPolygon([]geometry.Polygon{
+				geometry.Polygon([]geometry.Ring{
+					geometry.Ring([]geometry.Point{
+						geometry.Point([]float64{0, 0}),
+						geometry.Point([]float64{1, 0}),
+						geometry.Point([]float64{1, 1}),
+						geometry.Point([]float64{0, 0}),
+					}),
+				}),
+			})),
+			JSON: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}`,
+		},
+		{
+			Geometry: NewGeometry(geometry.Collection{
+				geometry.Point([]float64{1, 2}),
+				geometry.Collection{},
+			}),
+			JSON: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"GeometryCollection","geometries":[]}]}`,
+		},
+	}
+
+	for i, test := range testCases {
+		encoded, err := json.Marshal(test.Geometry)
+		if err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if string(encoded) != test.JSON {
+			t.Errorf("Case [%d]: Expected %s, got %s", i, test.JSON, encoded)
+		}
+
+		decoded := &Geometry{}
+		if err := json.Unmarshal([]byte(test.JSON), decoded); err != nil {
+			t.Fatalf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+		if !reflect.DeepEqual(test.Geometry, decoded) {
+			t.Errorf("Case [%d]: Expected %+v, got %+v", i, test.Geometry, decoded)
+		}
+	}
+}
+
+func TestGeometryJSONInvalid(t *testing.T) {
+	testCases := []string{
+		`{"type":"Circle","coordinates":[1,2]}`,
+		`{"type":"Point"}`,
+		`{"type":"Point","coordinates":[[1,2]]}`,
+	}
+
+	for i, test := range testCases {
+		decoded := &Geometry{}
+		if err := json.Unmarshal([]byte(test), decoded); err == nil {
+			t.Errorf("Case [%d]: Expected an error, got %+v", i, decoded)
+		}
+	}
+}
+
+func TestGeometryJSONCustom(t *testing.T) {
+	source := `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2],"title":"child"}],"crs":{"type":"name"}}`
+
+	decoded := &Geometry{}
+	if err := json.Unmarshal([]byte(source), decoded); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	child := NewGeometry(geometry.Point([]float64{1, 2}))
+	child.Custom = Properties{"title": "child"}
+	expected := &Geometry{
+		Type:       GeometryCollectionType,
+		Geometries: []*Geometry{child},
+		Custom:     Properties{"crs": map[string]interface{}{"type": "name"}},
+	}
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if string(encoded) != source {
+		t.Errorf("Expected %s, got %s", source, encoded)
+	}
+}
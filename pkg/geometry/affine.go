@@ -0,0 +1,39 @@
+package geometry
+
+import (
+	"math"
+)
+
+// Affine maps grid coordinates, such as those of a tile, to world
+// coordinates: x·Scale[0] + Translate[0] and y·Scale[1] + Translate[1].
+// Only the first two dimensions are mapped.
+type Affine struct {
+	Scale     [2]float64
+	Translate [2]float64
+}
+
+// TileAffine returns the affine transform from the grid of the tile z/x/y,
+// extent units wide, to Web Mercator metres. The grid's y axis points down,
+// as in vector tiles, so (0, 0) is the tile's north west corner.
+func TileAffine(z, x, y, extent uint32) Affine {
+	world := 2 * math.Pi * mercatorRadius
+	size := world / math.Exp2(float64(z))
+	scale := size / float64(extent)
+	return Affine{
+		Scale:     [2]float64{scale, -scale},
+		Translate: [2]float64{-world/2 + float64(x)*size, world/2 - float64(y)*size},
+	}
+}
+
+// Apply maps a point on the grid to the world.
+func (a Affine) Apply(p Point) Point {
+	x, y := xy(p)
+	return withXY(p, x*a.Scale[0]+a.Translate[0], y*a.Scale[1]+a.Translate[1])
+}
+
+// Invert maps a point in the world to the grid. The result isn't rounded,
+// so it may lie between grid points.
+func (a Affine) Invert(p Point) Point {
+	x, y := xy(p)
+	return withXY(p, (x-a.Translate[0])/a.Scale[0], (y-a.Translate[1])/a.Scale[1])
+}
@@ -0,0 +1,119 @@
+package geometry
+
+// Bound is the extent of a geometry: the minimum and maximum of each
+// dimension its points have. Points with fewer dimensions than others only
+// count towards the dimensions they have. Empty geometries have an empty
+// bound.
+type Bound struct {
+	Min Point
+	Max Point
+}
+
+// IsEmpty reports whether the bound contains no points at all.
+func (b Bound) IsEmpty() bool {
+	return len(b.Min) == 0
+}
+
+// Extend grows the bound to include point.
+func (b *Bound) Extend(point Point) {
+	for i, val := range point {
+		if i >= len(b.Min) {
+			b.Min = append(b.Min, val)
+			b.Max = append(b.Max, val)
+			continue
+		}
+		if val < b.Min[i] {
+			b.Min[i] = val
+		}
+		if val > b.Max[i] {
+			b.Max[i] = val
+		}
+	}
+}
+
+// Union grows the bound to include other.
+func (b *Bound) Union(other Bound) {
+	if other.IsEmpty() {
+		return
+	}
+	b.Extend(other.Min)
+	b.Extend(other.Max)
+}
+
+// Intersects reports whether the two bounds overlap, edges included, in
+// every dimension both of them have.
+func (b Bound) Intersects(other Bound) bool {
+	if b.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	for i := 0; i < len(b.Min) && i < len(other.Min); i++ {
+		if b.Max[i] < other.Min[i] || other.Max[i] < b.Min[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether point lies within the bound, edges included, in
+// every dimension both of them have.
+func (b Bound) Contains(point Point) bool {
+	return b.Intersects(Bound{Min: point, Max: point})
+}
+
+func (p Point) Bound() Bound {
+	b := Bound{}
+	b.Extend(p)
+	return b
+}
+
+func (m MultiPoint) Bound() Bound {
+	b := Bound{}
+	for _, p := range m {
+		b.Extend(p)
+	}
+	return b
+}
+
+func (ls LineString) Bound() Bound {
+	return MultiPoint(ls).Bound()
+}
+
+func (m MultiLineString) Bound() Bound {
+	b := Bound{}
+	for _, ls := range m {
+		b.Union(ls.Bound())
+	}
+	return b
+}
+
+func (r Ring) Bound() Bound {
+	return MultiPoint(r).Bound()
+}
+
+// Bound covers holes too, since they only lie within the exterior ring in
+// the first two dimensions.
+func (p Polygon) Bound() Bound {
+	b := Bound{}
+	for _, r := range p {
+		b.Union(r.Bound())
+	}
+	return b
+}
+
+func (mp MultiPolygon) Bound() Bound {
+	b := Bound{}
+	for _, p := range mp {
+		b.Union(p.Bound())
+	}
+	return b
+}
+
+func (c Collection) Bound() Bound {
+	b := Bound{}
+	for _, g := range c {
+		if g != nil {
+			b.Union(g.Bound())
+		}
+	}
+	return b
+}
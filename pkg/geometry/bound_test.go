@@ -0,0 +1,49 @@
+package geometry_test
+
+import (
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestBoundIntersects(t *testing.T) {
+	square := Bound{Min: Point{0, 0}, Max: Point{2, 2}}
+	testCases := []struct {
+		Other    Bound
+		Expected bool
+	}{
+		{Other: Bound{Min: Point{1, 1}, Max: Point{3, 3}}, Expected: true},
+		{Other: Bound{Min: Point{2, 2}, Max: Point{3, 3}}, Expected: true},
+		{Other: Bound{Min: Point{2.5, 0}, Max: Point{3, 2}}, Expected: false},
+		{Other: Bound{Min: Point{1, 1, 100}, Max: Point{1, 1, 200}}, Expected: true},
+		{Other: Bound{}, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := square.Intersects(test.Other); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+		if got := test.Other.Intersects(square); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t reversed, got %t", i, test.Expected, got)
+		}
+	}
+}
+
+func TestBoundContains(t *testing.T) {
+	b := LineString{Point{0, 0}, Point{2, 2}}.Bound()
+	testCases := []struct {
+		Point    Point
+		Expected bool
+	}{
+		{Point: Point{1, 1}, Expected: true},
+		{Point: Point{2, 0}, Expected: true},
+		{Point: Point{2, 2.5}, Expected: false},
+		{Point: Point{-1, 1}, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := b.Contains(test.Point); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+	}
+}
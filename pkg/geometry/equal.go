@@ -0,0 +1,268 @@
+package geometry
+
+import (
+	"math"
+)
+
+// comparison compares geometries point by point, allowing each coordinate
+// to differ by up to epsilon. Topological comparisons ignore the order of
+// the parts of multi geometries and collections, the direction of lines
+// and rings, and where rings start.
+type comparison struct {
+	epsilon     float64
+	topological bool
+}
+
+func (c comparison) points(a, b Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !(math.Abs(a[i]-b[i]) <= c.epsilon) {
+			return false
+		}
+	}
+	return true
+}
+
+// sequence compares points in order, or in either direction for
+// topological comparisons.
+func (c comparison) sequence(a, b []Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	forward, backward := true, c.topological
+	for i := range a {
+		forward = forward && c.points(a[i], b[i])
+		backward = backward && c.points(a[i], b[len(b)-1-i])
+		if !forward && !backward {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparison) ring(a, b Ring) bool {
+	if !c.topological {
+		return c.sequence(a, b)
+	}
+
+	// Leave out the closing points, then look for the rest of a in b
+	// starting from each point that matches a's first one
+	a, b = c.open(a), c.open(b)
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	n := len(a)
+	for start := range b {
+		if !c.points(a[0], b[start]) {
+			continue
+		}
+		forward, backward := true, true
+		for i := 1; i < n && (forward || backward); i++ {
+			forward = forward && c.points(a[i], b[(start+i)%n])
+			backward = backward && c.points(a[i], b[(start-i+n)%n])
+		}
+		if forward || backward {
+			return true
+		}
+	}
+	return false
+}
+
+// open drops the closing point of a ring.
+func (c comparison) open(r Ring) Ring {
+	if len(r) > 1 && c.points(r[0], r[len(r)-1]) {
+		return r[:len(r)-1]
+	}
+	return r
+}
+
+func (c comparison) polygon(a, b Polygon) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	if !c.ring(a[0], b[0]) {
+		return false
+	}
+	// Holes may come in any order for topological comparisons
+	return c.parts(len(a)-1, func(i, j int) bool {
+		return c.ring(a[i+1], b[j+1])
+	})
+}
+
+// parts compares n parts of two geometries with equal, in order, or by
+// matching each part of the first with a part of the second of its own for
+// topological comparisons.
+func (c comparison) parts(n int, equal func(i, j int) bool) bool {
+	if !c.topological {
+		for i := 0; i < n; i++ {
+			if !equal(i, i) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Within epsilon a part can equal several others, so taking the first
+	// free one could leave a later part without a match. A part whose
+	// matches are all taken moves one of the parts they're taken by on to
+	// another match instead, if it has any.
+	matches := make([]int, n)
+	for j := range matches {
+		matches[j] = -1
+	}
+	var match func(i int, visited []bool) bool
+	match = func(i int, visited []bool) bool {
+		for j := 0; j < n; j++ {
+			if visited[j] || !equal(i, j) {
+				continue
+			}
+			visited[j] = true
+			if matches[j] < 0 || match(matches[j], visited) {
+				matches[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if !match(i, make([]bool, n)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparison) geometry(a, b Geometry) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch t := a.(type) {
+	case Point:
+		other, ok := b.(Point)
+		return ok && c.points(t, other)
+	case MultiPoint:
+		other, ok := b.(MultiPoint)
+		return ok && len(t) == len(other) && c.parts(len(t), func(i, j int) bool {
+			return c.points(t[i], other[j])
+		})
+	case LineString:
+		other, ok := b.(LineString)
+		return ok && c.sequence(t, other)
+	case MultiLineString:
+		other, ok := b.(MultiLineString)
+		return ok && len(t) == len(other) && c.parts(len(t), func(i, j int) bool {
+			return c.sequence(t[i], other[j])
+		})
+	case Ring:
+		other, ok := b.(Ring)
+		return ok && c.ring(t, other)
+	case Polygon:
+		other, ok := b.(Polygon)
+		return ok && c.polygon(t, other)
+	case MultiPolygon:
+		other, ok := b.(MultiPolygon)
+		return ok && len(t) == len(other) && c.parts(len(t), func(i, j int) bool {
+			return c.polygon(t[i], other[j])
+		})
+	case Collection:
+		other, ok := b.(Collection)
+		return ok && len(t) == len(other) && c.parts(len(t), func(i, j int) bool {
+			return c.geometry(t[i], other[j])
+		})
+	}
+	return false
+}
+
+// Equal reports whether the two collections hold equal geometries in the
+// same order.
+func (c Collection) Equal(other Collection) bool {
+	return comparison{}.geometry(c, other)
+}
+
+// EqualWithin is Equal, but allows each coordinate to differ by up to
+// epsilon, such as after encoding at a lower precision.
+func (p Point) EqualWithin(other Point, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(p, other)
+}
+
+func (m MultiPoint) EqualWithin(other MultiPoint, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(m, other)
+}
+
+func (ls LineString) EqualWithin(other LineString, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(ls, other)
+}
+
+func (m MultiLineString) EqualWithin(other MultiLineString, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(m, other)
+}
+
+func (r Ring) EqualWithin(other Ring, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(r, other)
+}
+
+func (p Polygon) EqualWithin(other Polygon, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(p, other)
+}
+
+func (mp MultiPolygon) EqualWithin(other MultiPolygon, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(mp, other)
+}
+
+func (c Collection) EqualWithin(other Collection, epsilon float64) bool {
+	return comparison{epsilon: epsilon}.geometry(c, other)
+}
+
+// EqualTopology is EqualWithin for points, which have no topology to
+// speak of.
+func (p Point) EqualTopology(other Point, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(p, other)
+}
+
+// EqualTopology is EqualWithin, but ignores the order of the points.
+func (m MultiPoint) EqualTopology(other MultiPoint, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(m, other)
+}
+
+// EqualTopology is EqualWithin, but also accepts the line reversed.
+func (ls LineString) EqualTopology(other LineString, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(ls, other)
+}
+
+// EqualTopology is EqualWithin, but ignores the order and direction of the
+// lines.
+func (m MultiLineString) EqualTopology(other MultiLineString, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(m, other)
+}
+
+// EqualTopology is EqualWithin, but ignores which point the rings start at
+// and which way they wind.
+func (r Ring) EqualTopology(other Ring, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(r, other)
+}
+
+// EqualTopology is Ring.EqualTopology for each ring, and ignores the order
+// of the holes.
+func (p Polygon) EqualTopology(other Polygon, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(p, other)
+}
+
+// EqualTopology is Polygon.EqualTopology for each polygon, and ignores
+// their order.
+func (mp MultiPolygon) EqualTopology(other MultiPolygon, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(mp, other)
+}
+
+// EqualTopology compares the geometries of the collections topologically,
+// ignoring their order.
+func (c Collection) EqualTopology(other Collection, epsilon float64) bool {
+	return comparison{epsilon: epsilon, topological: true}.geometry(c, other)
+}
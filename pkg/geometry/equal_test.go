@@ -0,0 +1,132 @@
+package geometry_test
+
+import (
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestEqualWithin(t *testing.T) {
+	testCases := []struct {
+		A, B     Point
+		Epsilon  float64
+		Expected bool
+	}{
+		{A: Point{1, 2}, B: Point{1, 2}, Epsilon: 0, Expected: true},
+		{A: Point{1, 2}, B: Point{1.0000001, 2}, Epsilon: 0, Expected: false},
+		{A: Point{1, 2}, B: Point{1.0000001, 1.9999999}, Epsilon: 1e-6, Expected: true},
+		{A: Point{1, 2}, B: Point{1.001, 2}, Epsilon: 1e-6, Expected: false},
+		{A: Point{1, 2}, B: Point{1, 2, 0}, Epsilon: 1, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := test.A.EqualWithin(test.B, test.Epsilon); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+	}
+}
+
+func TestEqualWithinOrder(t *testing.T) {
+	polygon := Polygon{counterClockwise, clockwise}
+	shifted := Polygon{
+		{{0, 0}, {4, 0.0000001}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2.0000001, 1}, {1, 1}},
+	}
+	if !polygon.EqualWithin(shifted, 1e-6) {
+		t.Errorf("Expected %v to equal %v", polygon, shifted)
+	}
+	if polygon.EqualWithin(Polygon{counterClockwise.Reverse(), clockwise}, 1e-6) {
+		t.Errorf("Expected a reversed ring to differ")
+	}
+	if (MultiLineString{{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}}}).EqualWithin(MultiLineString{{{2, 2}, {3, 3}}, {{0, 0}, {1, 1}}}, 1) {
+		t.Errorf("Expected lines in another order to differ")
+	}
+}
+
+func TestRingEqualTopology(t *testing.T) {
+	ring := Ring{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	testCases := []struct {
+		Ring     Ring
+		Expected bool
+	}{
+		{Ring: ring, Expected: true},
+		// Starting elsewhere
+		{Ring: Ring{{4, 4}, {0, 4}, {0, 0}, {4, 0}, {4, 4}}, Expected: true},
+		// Winding the other way
+		{Ring: Ring{{4, 0}, {0, 0}, {0, 4}, {4, 4}, {4, 0}}, Expected: true},
+		// Without the closing point
+		{Ring: Ring{{0, 4}, {0, 0}, {4, 0}, {4, 4}}, Expected: true},
+		{Ring: Ring{{0, 0}, {4, 0}, {0, 4}, {4, 4}, {0, 0}}, Expected: false},
+		{Ring: Ring{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := ring.EqualTopology(test.Ring, 0); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+	}
+}
+
+func TestEqualTopology(t *testing.T) {
+	square := Ring{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	holeA := Ring{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}}
+	holeB := Ring{{3, 3}, {3, 3.5}, {3.5, 3.5}, {3.5, 3}, {3, 3}}
+	polygon := Polygon{square, holeA, holeB}
+
+	other := Polygon{square.Reverse(), holeB, Ring{{2, 2}, {2, 1}, {1, 1}, {1, 2}, {2, 2}}}
+	if !polygon.EqualTopology(other, 0) {
+		t.Errorf("Expected %v to equal %v", polygon, other)
+	}
+	if polygon.EqualTopology(Polygon{holeA, square, holeB}, 0) {
+		t.Errorf("Expected a different exterior ring to differ")
+	}
+
+	if !(MultiPolygon{polygon, {holeA}}).EqualTopology(MultiPolygon{{holeA}, other}, 0) {
+		t.Errorf("Expected polygons in another order to be equal")
+	}
+	if !(LineString{{0, 0}, {1, 1}, {2, 0}}).EqualTopology(LineString{{2, 0}, {1, 1}, {0, 0}}, 0) {
+		t.Errorf("Expected a reversed line to be equal")
+	}
+	if !(MultiPoint{{0, 0}, {1, 1}, {1, 1}}).EqualTopology(MultiPoint{{1, 1}, {0, 0}, {1, 1}}, 0) {
+		t.Errorf("Expected points in another order to be equal")
+	}
+	if (MultiPoint{{0, 0}, {1, 1}, {1, 1}}).EqualTopology(MultiPoint{{1, 1}, {0, 0}, {0, 0}}, 0) {
+		t.Errorf("Expected points repeated a different number of times to differ")
+	}
+
+	// {1, 0} is within 1 of both points, but {0, 0} only of {0.5, 0}
+	a, b := MultiPoint{{1, 0}, {0, 0}}, MultiPoint{{0.5, 0}, {2, 0}}
+	if !a.EqualTopology(b, 1) || !b.EqualTopology(a, 1) {
+		t.Errorf("Expected %v to equal %v", a, b)
+	}
+	if (MultiPoint{{1, 0}, {0, 0}}).EqualTopology(MultiPoint{{0.5, 0}, {0.5, 0.5}}, 0.4) {
+		t.Errorf("Expected points with no match to differ")
+	}
+}
+
+func TestCollectionEqual(t *testing.T) {
+	collection := Collection{Point{1, 2}, LineString{{0, 0}, {1, 1}}, Collection{Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}
+	testCases := []struct {
+		Collection Collection
+		Expected   bool
+	}{
+		{Collection: Collection{Point{1, 2}, LineString{{0, 0}, {1, 1}}, Collection{Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, Expected: true},
+		{Collection: Collection{LineString{{0, 0}, {1, 1}}, Point{1, 2}, Collection{Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, Expected: false},
+		{Collection: Collection{Point{1, 2}, MultiPoint{{0, 0}, {1, 1}}, Collection{Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, Expected: false},
+		{Collection: Collection{Point{1, 2}, LineString{{0, 0}, {1, 1}}}, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := collection.Equal(test.Collection); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+	}
+
+	reordered := Collection{Collection{Ring{{1, 1}, {0, 0}, {1, 0}, {1, 1}}}, LineString{{1, 1}, {0, 0}}, Point{1, 2.0000001}}
+	if !collection.EqualTopology(reordered, 1e-6) {
+		t.Errorf("Expected %v to equal %v topologically", collection, reordered)
+	}
+	if collection.EqualWithin(reordered, 1e-6) {
+		t.Errorf("Expected %v to differ from %v", collection, reordered)
+	}
+}
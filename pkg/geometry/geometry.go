@@ -1,6 +1,7 @@
 package geometry
 
 type Geometry interface {
+	Bound() Bound
 	private()
 }
 
@@ -74,6 +75,22 @@ func (r Ring) Equal(ring Ring) bool {
 	return MultiPoint(r).Equal(MultiPoint(ring))
 }
 
+// IsClosed reports whether the ring ends at the point it starts from.
+func (r Ring) IsClosed() bool {
+	return len(r) > 1 && r[0].Equal(r[len(r)-1])
+}
+
+// Close returns the ring with its first point appended, unless it's closed
+// already. The ring itself is left untouched.
+func (r Ring) Close() Ring {
+	if len(r) == 0 || r.IsClosed() {
+		return r
+	}
+	closed := make(Ring, len(r), len(r)+1)
+	copy(closed, r)
+	return append(closed, r[0])
+}
+
 type Polygon []Ring
 
 func (p Polygon) private() {}
@@ -0,0 +1,420 @@
+package geometry
+
+import (
+	"math"
+)
+
+// Planar measures only use the first two dimensions of points, and treat
+// unclosed rings as if they were closed. Rings, polygons and multi polygons
+// are areas: their distance to a point inside of them is 0.
+
+// Length returns the length of the line.
+func (ls LineString) Length() float64 {
+	return pathLength(ls, false)
+}
+
+func (m MultiLineString) Length() float64 {
+	sum := 0.0
+	for _, ls := range m {
+		sum += ls.Length()
+	}
+	return sum
+}
+
+// Length returns the perimeter of the ring.
+func (r Ring) Length() float64 {
+	return pathLength(r, true)
+}
+
+// Length returns the perimeter of the polygon, holes included.
+func (p Polygon) Length() float64 {
+	sum := 0.0
+	for _, r := range p {
+		sum += r.Length()
+	}
+	return sum
+}
+
+func (mp MultiPolygon) Length() float64 {
+	sum := 0.0
+	for _, p := range mp {
+		sum += p.Length()
+	}
+	return sum
+}
+
+// Length returns the summed length of the lines in the collection, and the
+// perimeters of its areas.
+func (c Collection) Length() float64 {
+	sum := 0.0
+	for _, g := range c {
+		if l, ok := g.(interface{ Length() float64 }); ok {
+			sum += l.Length()
+		}
+	}
+	return sum
+}
+
+func pathLength(points []Point, closed bool) float64 {
+	sum := 0.0
+	forEachSegment(points, closed, func(a, b Point) {
+		ax, ay := xy(a)
+		bx, by := xy(b)
+		sum += math.Hypot(bx-ax, by-ay)
+	})
+	return sum
+}
+
+// forEachSegment calls f with each pair of consecutive points, and with the
+// last and first point if closed is set and they differ.
+func forEachSegment(points []Point, closed bool, f func(a, b Point)) {
+	for i := 1; i < len(points); i++ {
+		f(points[i-1], points[i])
+	}
+	if closed && len(points) > 1 && !points[0].Equal(points[len(points)-1]) {
+		f(points[len(points)-1], points[0])
+	}
+}
+
+// Area returns the area the ring encloses.
+func (r Ring) Area() float64 {
+	return math.Abs(r.signedArea()) / 2
+}
+
+// Area returns the area of the exterior ring less that of the holes.
+func (p Polygon) Area() float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	area := p[0].Area()
+	for _, hole := range p[1:] {
+		area -= hole.Area()
+	}
+	return area
+}
+
+func (mp MultiPolygon) Area() float64 {
+	sum := 0.0
+	for _, p := range mp {
+		sum += p.Area()
+	}
+	return sum
+}
+
+// Area returns the summed area of the areas in the collection.
+func (c Collection) Area() float64 {
+	sum := 0.0
+	for _, g := range c {
+		if a, ok := g.(interface{ Area() float64 }); ok {
+			sum += a.Area()
+		}
+	}
+	return sum
+}
+
+// centroid sums up weighted coordinates, keeping only those of the highest
+// dimension added: areas outweigh lines, which outweigh points.
+type centroid struct {
+	dimension int
+	x, y      float64
+	weight    float64
+}
+
+func (c *centroid) add(dimension int, x, y, weight float64) {
+	if dimension < c.dimension {
+		return
+	}
+	if dimension > c.dimension {
+		*c = centroid{dimension: dimension}
+	}
+	c.x += x
+	c.y += y
+	c.weight += weight
+}
+
+func (c *centroid) point() Point {
+	if c.weight == 0 {
+		return nil
+	}
+	return Point{c.x / c.weight, c.y / c.weight}
+}
+
+func (c *centroid) addPoints(points []Point) {
+	for _, p := range points {
+		x, y := xy(p)
+		c.add(0, x, y, 1)
+	}
+}
+
+// addPath adds the middle of each segment, weighted by its length. Paths
+// without a length count as their points.
+func (c *centroid) addPath(points []Point, closed bool) {
+	length := pathLength(points, closed)
+	if length == 0 {
+		c.addPoints(points)
+		return
+	}
+	forEachSegment(points, closed, func(a, b Point) {
+		ax, ay := xy(a)
+		bx, by := xy(b)
+		l := math.Hypot(bx-ax, by-ay)
+		c.add(1, (ax+bx)/2*l, (ay+by)/2*l, l)
+	})
+}
+
+// addPolygon adds the centroids of the rings weighted by their area, with
+// holes taking theirs away. Polygons without an area count as their rings.
+func (c *centroid) addPolygon(p Polygon) {
+	if p.Area() == 0 {
+		for _, r := range p {
+			c.addPath(r, true)
+		}
+		return
+	}
+	for i, r := range p {
+		x, y, area := r.moments()
+		if i > 0 {
+			x, y, area = -x, -y, -area
+		}
+		c.add(2, x, y, area)
+	}
+}
+
+// moments returns the first moments of area of the ring along with its
+// area, all of them positive whichever way the ring winds.
+func (r Ring) moments() (float64, float64, float64) {
+	var x, y, area float64
+	for i, p := range r {
+		px, py := xy(p)
+		nx, ny := xy(r[(i+1)%len(r)])
+		cross := px*ny - nx*py
+		x += (px + nx) * cross
+		y += (py + ny) * cross
+		area += cross
+	}
+	if area < 0 {
+		x, y, area = -x, -y, -area
+	}
+	return x / 6, y / 6, area / 2
+}
+
+func (c *centroid) addGeometry(g Geometry) {
+	switch t := g.(type) {
+	case Point:
+		c.addPoints([]Point{t})
+	case MultiPoint:
+		c.addPoints(t)
+	case LineString:
+		c.addPath(t, false)
+	case MultiLineString:
+		for _, ls := range t {
+			c.addPath(ls, false)
+		}
+	case Ring:
+		c.addPolygon(Polygon{t})
+	case Polygon:
+		c.addPolygon(t)
+	case MultiPolygon:
+		for _, p := range t {
+			c.addPolygon(p)
+		}
+	case Collection:
+		for _, child := range t {
+			c.addGeometry(child)
+		}
+	}
+}
+
+// Centroid returns the average of the points, or nil if there are none.
+func (m MultiPoint) Centroid() Point {
+	return centroidOf(m)
+}
+
+// Centroid returns the centre of mass of the line, or nil if it's empty.
+func (ls LineString) Centroid() Point {
+	return centroidOf(ls)
+}
+
+func (m MultiLineString) Centroid() Point {
+	return centroidOf(m)
+}
+
+// Centroid returns the centre of mass of the area the ring encloses, or of
+// the ring itself if it doesn't enclose any.
+func (r Ring) Centroid() Point {
+	return centroidOf(r)
+}
+
+// Centroid returns the centre of mass of the polygon, taking its holes into
+// account. It may lie outside of the polygon.
+func (p Polygon) Centroid() Point {
+	return centroidOf(p)
+}
+
+func (mp MultiPolygon) Centroid() Point {
+	return centroidOf(mp)
+}
+
+// Centroid returns the centroid of the collection's highest dimension
+// geometries, so that e.g. points have no say once there are lines.
+func (c Collection) Centroid() Point {
+	return centroidOf(c)
+}
+
+func centroidOf(g Geometry) Point {
+	c := &centroid{}
+	c.addGeometry(g)
+	return c.point()
+}
+
+// Distance returns the distance between the two points.
+func (p Point) Distance(point Point) float64 {
+	return math.Sqrt(sqDistance(p, point))
+}
+
+// Distance returns the distance from point to the closest of the points,
+// or +Inf if there are none.
+func (m MultiPoint) Distance(point Point) float64 {
+	return distanceTo(m, point)
+}
+
+// Distance returns the distance from point to the closest point on the
+// line, or +Inf if the line is empty.
+func (ls LineString) Distance(point Point) float64 {
+	return distanceTo(ls, point)
+}
+
+func (m MultiLineString) Distance(point Point) float64 {
+	return distanceTo(m, point)
+}
+
+// Distance returns the distance from point to the ring, which is 0 if it
+// lies within the ring.
+func (r Ring) Distance(point Point) float64 {
+	return distanceTo(r, point)
+}
+
+// Distance returns the distance from point to the polygon, which is 0 if
+// it lies within the polygon and outside of its holes.
+func (p Polygon) Distance(point Point) float64 {
+	return distanceTo(p, point)
+}
+
+func (mp MultiPolygon) Distance(point Point) float64 {
+	return distanceTo(mp, point)
+}
+
+func (c Collection) Distance(point Point) float64 {
+	return distanceTo(c, point)
+}
+
+func distanceTo(g Geometry, point Point) float64 {
+	return math.Sqrt(sqDistanceTo(g, point))
+}
+
+func sqDistance(a, b Point) float64 {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	return (bx-ax)*(bx-ax) + (by-ay)*(by-ay)
+}
+
+func sqDistanceTo(g Geometry, point Point) float64 {
+	min := math.Inf(1)
+	switch t := g.(type) {
+	case Point:
+		min = sqDistance(t, point)
+	case MultiPoint:
+		for _, p := range t {
+			min = math.Min(min, sqDistance(p, point))
+		}
+	case LineString:
+		min = sqPathDistance(t, false, point)
+	case MultiLineString:
+		for _, ls := range t {
+			min = math.Min(min, sqPathDistance(ls, false, point))
+		}
+	case Ring:
+		min = sqDistanceTo(Polygon{t}, point)
+	case Polygon:
+		if len(t) > 0 && t.locate(point) >= 0 {
+			return 0
+		}
+		for _, r := range t {
+			min = math.Min(min, sqPathDistance(r, true, point))
+		}
+	case MultiPolygon:
+		for _, p := range t {
+			min = math.Min(min, sqDistanceTo(p, point))
+		}
+	case Collection:
+		for _, child := range t {
+			min = math.Min(min, sqDistanceTo(child, point))
+		}
+	}
+	return min
+}
+
+func sqPathDistance(points []Point, closed bool, point Point) float64 {
+	if len(points) == 1 {
+		return sqDistance(points[0], point)
+	}
+	min := math.Inf(1)
+	forEachSegment(points, closed, func(a, b Point) {
+		min = math.Min(min, sqSegmentDistance(point, a, b))
+	})
+	return min
+}
+
+// Locate is 1 if point lies inside the ring, 0 if it lies on one of its
+// edges and -1 if it lies outside of it. Like Orientation, the answer is
+// exact.
+func (r Ring) Locate(point Point) int {
+	_, py := xy(point)
+	inside := false
+	onEdge := false
+	forEachSegment(r, true, func(a, b Point) {
+		if onEdge {
+			return
+		}
+		if OnSegment(point, a, b) {
+			onEdge = true
+			return
+		}
+		// An edge crossing the horizontal line through point passes right
+		// of it if point lies left of the edge going up
+		_, ay := xy(a)
+		_, by := xy(b)
+		if (ay > py) != (by > py) && (Orientation(a, b, point) > 0) == (by > ay) {
+			inside = !inside
+		}
+	})
+	switch {
+	case onEdge:
+		return 0
+	case inside:
+		return 1
+	}
+	return -1
+}
+
+// locate is 1 if point lies inside the polygon, 0 if it lies on the edge of
+// its exterior ring or of a hole and -1 if it lies outside of it or inside a
+// hole.
+func (p Polygon) locate(point Point) int {
+	if len(p) == 0 {
+		return -1
+	}
+	location := p[0].Locate(point)
+	if location < 0 {
+		return -1
+	}
+	for _, hole := range p[1:] {
+		switch hole.Locate(point) {
+		case 1:
+			return -1
+		case 0:
+			location = 0
+		}
+	}
+	return location
+}
@@ -0,0 +1,248 @@
+package geometry_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func withinTolerance(expected, got, tolerance float64) bool {
+	if math.IsInf(expected, 0) {
+		return expected == got
+	}
+	return math.Abs(expected-got) <= tolerance
+}
+
+// donut is a 10 by 10 square with a 2 by 2 hole.
+var donut = Polygon{
+	{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+	{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+}
+
+func TestLength(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ Length() float64 }
+		Expected float64
+	}{
+		{Geometry: LineString{{0, 0}, {3, 4}, {3, 10}}, Expected: 11},
+		{Geometry: LineString{{0, 0}}, Expected: 0},
+		{Geometry: MultiLineString{{{0, 0}, {3, 4}}, {{0, 0}, {0, 1}}}, Expected: 6},
+		{Geometry: Ring{{0, 0}, {3, 0}, {3, 4}, {0, 0}}, Expected: 12},
+		// Unclosed rings count their closing edge
+		{Geometry: Ring{{0, 0}, {3, 0}, {3, 4}}, Expected: 12},
+		{Geometry: donut, Expected: 48},
+		{Geometry: MultiPolygon{donut, {donut[1]}}, Expected: 56},
+		{Geometry: Collection{Point{1, 2}, LineString{{0, 0}, {0, 2}}, donut}, Expected: 50},
+	}
+
+	for i, test := range testCases {
+		if got := test.Geometry.Length(); !withinTolerance(test.Expected, got, 1e-9) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestArea(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ Area() float64 }
+		Expected float64
+	}{
+		{Geometry: Ring{{0, 0}, {3, 0}, {3, 4}, {0, 0}}, Expected: 6},
+		{Geometry: Ring{{0, 0}, {3, 4}, {3, 0}, {0, 0}}, Expected: 6},
+		{Geometry: donut, Expected: 96},
+		{Geometry: MultiPolygon{donut, {donut[1]}}, Expected: 100},
+		{Geometry: Collection{Point{1, 2}, LineString{{0, 0}, {0, 2}}, donut}, Expected: 96},
+	}
+
+	for i, test := range testCases {
+		if got := test.Geometry.Area(); !withinTolerance(test.Expected, got, 1e-9) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ Centroid() Point }
+		Expected Point
+	}{
+		{Geometry: MultiPoint{{0, 0}, {2, 0}, {2, 4}}, Expected: Point{4.0 / 3, 4.0 / 3}},
+		{Geometry: MultiPoint{}, Expected: nil},
+		// Weighted by length rather than by points
+		{Geometry: LineString{{0, 0}, {8, 0}, {8, 2}}, Expected: Point{4.8, 0.2}},
+		{Geometry: MultiLineString{{{0, 0}, {0, 0}}}, Expected: Point{0, 0}},
+		{Geometry: Ring{{0, 0}, {6, 0}, {0, 6}, {0, 0}}, Expected: Point{2, 2}},
+		// Rings without an area fall back to their edges
+		{Geometry: Ring{{0, 0}, {4, 0}, {0, 0}}, Expected: Point{2, 0}},
+		{Geometry: Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0}}}, Expected: Point{7.0 / 3, 7.0 / 3}},
+		{Geometry: MultiPolygon{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}, {{{4, 0}, {6, 0}, {6, 2}, {4, 2}, {4, 0}}}}, Expected: Point{3, 1}},
+		// Areas outweigh lines and points
+		{Geometry: Collection{Point{100, 100}, LineString{{50, 50}, {60, 60}}, donut}, Expected: Point{61.0 / 12, 61.0 / 12}},
+		{Geometry: Collection{Point{100, 100}, LineString{{0, 0}, {0, 2}}}, Expected: Point{0, 1}},
+	}
+
+	for i, test := range testCases {
+		got := test.Geometry.Centroid()
+		if test.Expected == nil {
+			if got != nil {
+				t.Errorf("Case [%d]: Expected nil, got %v", i, got)
+			}
+			continue
+		}
+		if len(got) != 2 || !withinTolerance(test.Expected[0], got[0], 1e-9) || !withinTolerance(test.Expected[1], got[1], 1e-9) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ Distance(Point) float64 }
+		Point    Point
+		Expected float64
+	}{
+		{Geometry: Point{0, 0}, Point: Point{3, 4}, Expected: 5},
+		{Geometry: MultiPoint{{0, 0}, {3, 3}}, Point: Point{3, 4}, Expected: 1},
+		{Geometry: MultiPoint{}, Point: Point{3, 4}, Expected: math.Inf(1)},
+		{Geometry: LineString{{0, 0}, {10, 0}}, Point: Point{5, 2}, Expected: 2},
+		{Geometry: LineString{{0, 0}, {10, 0}}, Point: Point{13, 4}, Expected: 5},
+		{Geometry: MultiLineString{{{0, 0}, {10, 0}}, {{0, 3}, {10, 3}}}, Point: Point{5, 2}, Expected: 1},
+		{Geometry: Ring{{0, 0}, {10, 0}, {10, 10}, {0, 0}}, Point: Point{9, 1}, Expected: 0},
+		{Geometry: donut, Point: Point{1, 1}, Expected: 0},
+		{Geometry: donut, Point: Point{2, 3}, Expected: 0},
+		{Geometry: donut, Point: Point{3, 3}, Expected: 1},
+		{Geometry: donut, Point: Point{13, 14}, Expected: 5},
+		{Geometry: MultiPolygon{donut}, Point: Point{-1, 5}, Expected: 1},
+		{Geometry: Collection{Point{20, 20}, donut}, Point: Point{15, 5}, Expected: 5},
+	}
+
+	for i, test := range testCases {
+		if got := test.Geometry.Distance(test.Point); !withinTolerance(test.Expected, got, 1e-9) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+// oneDegree is the length of a degree along a great circle.
+const oneDegree = EarthRadius * math.Pi / 180
+
+func TestSphericalLength(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ SphericalLength() float64 }
+		Expected float64
+	}{
+		{Geometry: LineString{{0, 0}, {1, 0}}, Expected: oneDegree},
+		{Geometry: LineString{{0, 0}, {0, 90}}, Expected: 90 * oneDegree},
+		// London to Paris
+		{Geometry: LineString{{-0.1278, 51.5074}, {2.3522, 48.8566}}, Expected: 343556.53},
+		{Geometry: MultiLineString{{{0, 0}, {1, 0}}, {{0, 0}, {0, 2}}}, Expected: 3 * oneDegree},
+		{Geometry: Ring{{0, 0}, {1, 0}, {1, 1}}, Expected: 379639.76},
+		{Geometry: Collection{Point{1, 2}, LineString{{0, 0}, {1, 0}}}, Expected: oneDegree},
+	}
+
+	for i, test := range testCases {
+		// Within a millimetre, or a millimetre per kilometre for long lines
+		if got := test.Geometry.SphericalLength(); !withinTolerance(test.Expected, got, math.Max(1e-3, test.Expected*1e-6)) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestSphericalArea(t *testing.T) {
+	// A one degree cell on the equator is R²·(π/180)·sin(1°)
+	cell := EarthRadius * EarthRadius * math.Pi / 180 * math.Sin(math.Pi/180)
+	square := func(lon, lat float64) Ring {
+		return Ring{{lon, lat}, {lon + 1, lat}, {lon + 1, lat + 1}, {lon, lat + 1}, {lon, lat}}
+	}
+
+	testCases := []struct {
+		Geometry interface{ SphericalArea() float64 }
+		Expected float64
+	}{
+		{Geometry: square(0, 0), Expected: cell},
+		{Geometry: square(0, 0).Reverse(), Expected: cell},
+		{Geometry: square(0, -1), Expected: cell},
+		// The triangle between the equator and two meridians 90° apart is
+		// an eighth of a sphere's 4πR²
+		{Geometry: Ring{{0, 0}, {90, 0}, {0, 90}, {0, 0}}, Expected: math.Pi / 2 * EarthRadius * EarthRadius},
+		{Geometry: Ring{{0, 0}, {0, 90}, {90, 0}}, Expected: math.Pi / 2 * EarthRadius * EarthRadius},
+		// A square metre at the equator
+		{Geometry: square(0, 0).Transform(func(p Point) Point { return Point{p[0] / oneDegree, p[1] / oneDegree} }), Expected: 1},
+		{Geometry: Polygon{{{-1, -1}, {2, -1}, {2, 2}, {-1, 2}, {-1, -1}}, square(0, 0)}, Expected: 9*cell - cell},
+		{Geometry: MultiPolygon{{square(0, 0)}, {square(10, -1)}}, Expected: 2 * cell},
+		{Geometry: Collection{LineString{{0, 0}, {1, 1}}, square(0, 0)}, Expected: cell},
+	}
+
+	for i, test := range testCases {
+		if got := test.Geometry.SphericalArea(); !withinTolerance(test.Expected, got, test.Expected*1e-3) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestSphericalCentroid(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ SphericalCentroid() Point }
+		Expected Point
+	}{
+		{Geometry: MultiPoint{{-10, 0}, {10, 0}}, Expected: Point{0, 0}},
+		{Geometry: MultiPoint{{0, 0}, {180, 0}}, Expected: nil},
+		// Across the antimeridian
+		{Geometry: LineString{{170, 0}, {-170, 0}}, Expected: Point{180, 0}},
+		{Geometry: LineString{{0, 0}, {10, 0}, {10, 0.0001}}, Expected: Point{5, 0}},
+		{Geometry: Ring{{0, -5}, {10, -5}, {10, 5}, {0, 5}, {0, -5}}, Expected: Point{5, 0}},
+		{Geometry: Polygon{{{0, -0.5}, {1, -0.5}, {1, 0.5}, {0, 0.5}, {0, -0.5}}, {{0, -0.5}, {0.5, -0.5}, {0.5, 0.5}, {0, 0.5}, {0, -0.5}}}, Expected: Point{0.75, 0}},
+		{Geometry: MultiPolygon{{{{-2, -1}, {-1, -1}, {-1, 1}, {-2, 1}, {-2, -1}}}, {{{1, -1}, {2, -1}, {2, 1}, {1, 1}, {1, -1}}}}, Expected: Point{0, 0}},
+		{Geometry: Collection{Point{50, 50}, LineString{{-1, 0}, {1, 0}}}, Expected: Point{0, 0}},
+	}
+
+	for i, test := range testCases {
+		got := test.Geometry.SphericalCentroid()
+		if test.Expected == nil {
+			if got != nil {
+				t.Errorf("Case [%d]: Expected nil, got %v", i, got)
+			}
+			continue
+		}
+		if len(got) != 2 || !withinTolerance(math.Abs(test.Expected[0]), math.Abs(got[0]), 1e-3) || !withinTolerance(test.Expected[1], got[1], 1e-3) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestSphericalDistance(t *testing.T) {
+	testCases := []struct {
+		Geometry interface{ SphericalDistance(Point) float64 }
+		Point    Point
+		Expected float64
+	}{
+		{Geometry: Point{0, 0}, Point: Point{0, 1}, Expected: oneDegree},
+		{Geometry: Point{-0.1278, 51.5074}, Point: Point{2.3522, 48.8566}, Expected: 343556.53},
+		{Geometry: LineString{{-10, 0}, {10, 0}}, Point: Point{0, 1}, Expected: oneDegree},
+		{Geometry: LineString{{-10, 0}, {10, 0}}, Point: Point{20, 0}, Expected: 10 * oneDegree},
+		{Geometry: MultiLineString{{{-10, 0}, {10, 0}}, {{-10, 3}, {10, 3}}}, Point: Point{0, -1}, Expected: oneDegree},
+		{Geometry: Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, Point: Point{5, 5}, Expected: 0},
+		{Geometry: Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}, Point: Point{-2, 5}, Expected: 2 * oneDegree * math.Cos(5*math.Pi/180)},
+		{Geometry: MultiPolygon{{{{0, -10}, {10, -10}, {10, 10}, {0, 10}, {0, -10}}}}, Point: Point{-1, 0}, Expected: oneDegree},
+		{Geometry: Collection{Point{50, 50}, LineString{{-10, 0}, {10, 0}}}, Point: Point{0, -1}, Expected: oneDegree},
+	}
+
+	for i, test := range testCases {
+		if got := test.Geometry.SphericalDistance(test.Point); !withinTolerance(test.Expected, got, math.Max(1e-3, test.Expected*1e-3)) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestMeasuresIgnoreExtraDimensions(t *testing.T) {
+	flat := LineString{{0, 0}, {3, 4}}
+	raised := LineString{{0, 0, 100}, {3, 4, -100}}
+	if flat.Length() != raised.Length() {
+		t.Errorf("Expected %v, got %v", flat.Length(), raised.Length())
+	}
+	if !reflect.DeepEqual(flat.Centroid(), raised.Centroid()) {
+		t.Errorf("Expected %v, got %v", flat.Centroid(), raised.Centroid())
+	}
+}
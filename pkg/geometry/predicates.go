@@ -0,0 +1,614 @@
+package geometry
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// Predicates only use the first two dimensions of points, and follow the
+// OGC simple features model: an area's boundary is its rings, and a line's
+// boundary is its ends unless it's closed. Rings count as areas, like
+// polygons without holes.
+
+// Contains reports whether point lies inside the area the ring encloses.
+// Points on the ring itself aren't contained; Intersects includes them.
+func (r Ring) Contains(point Point) bool {
+	return Polygon{r}.Contains(point)
+}
+
+// Contains reports whether point lies inside the polygon and outside of
+// its holes. Points on the exterior ring or the edge of a hole aren't
+// contained; Intersects includes them.
+func (p Polygon) Contains(point Point) bool {
+	return p.locate(point) > 0
+}
+
+// Contains reports whether point lies inside any of the polygons.
+func (mp MultiPolygon) Contains(point Point) bool {
+	for _, p := range mp {
+		if p.Contains(point) {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersects reports whether a and b have at least one point in common,
+// boundaries included. Nil and empty geometries intersect nothing.
+func Intersects(a, b Geometry) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	pa, pb := partsOf(a), partsOf(b)
+	if !pa.box.intersects(pb.box) {
+		return false
+	}
+
+	// Unless the edges of a and b meet, every line and area of one either
+	// lies entirely inside the other or entirely outside of it, so checking
+	// a single point of each is enough
+	for _, seg := range pa.segments {
+		if !seg.box.intersects(pb.box) {
+			continue
+		}
+		for _, other := range pb.segments {
+			if seg.box.intersects(other.box) && SegmentsIntersect(seg.a, seg.b, other.a, other.b) {
+				return true
+			}
+		}
+	}
+	for _, p := range pa.representatives() {
+		if pb.locate(p) >= 0 {
+			return true
+		}
+	}
+	for _, p := range pb.representatives() {
+		if pa.locate(p) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Within reports whether a lies in b: no point of a lies outside of b, and
+// a doesn't only touch b's boundary. Nil and empty geometries lie within
+// nothing.
+//
+// Lines are compared piece by piece, splitting them where they meet the
+// edges of b, so a line following the edge of one polygon and then of its
+// neighbour is still within them. Likewise, an area may span neighbouring
+// polygons of b across the edges they share.
+func Within(a, b Geometry) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	pa, pb := partsOf(a), partsOf(b)
+	if len(pa.points) == 0 && len(pa.paths) == 0 && len(pa.polygons) == 0 {
+		return false
+	}
+	if !pb.box.contains(pa.box) {
+		return false
+	}
+
+	interior := false
+	for _, p := range pa.points {
+		switch pb.locate(p) {
+		case -1:
+			return false
+		case 1:
+			interior = true
+		}
+	}
+	location := 0
+	for _, seg := range pa.segments {
+		if seg.first {
+			location = 0
+		}
+		var covered, inside bool
+		covered, inside, location = pb.covers(seg, location)
+		if !covered {
+			return false
+		}
+		interior = interior || inside
+	}
+
+	for _, polygon := range pa.polygons {
+		if polygon.Area() == 0 {
+			continue
+		}
+		// Lines and points can't cover an area, and an area doesn't cover
+		// another if its boundary passes through the other's inside. Edges
+		// that neighbouring polygons of b share aren't part of its boundary
+		if len(pb.polygons) == 0 {
+			return false
+		}
+		rings := partsOf(polygon)
+		location := 0
+		for _, seg := range pb.segments {
+			if seg.first {
+				location = 0
+			}
+			if !seg.box.intersects(rings.box) {
+				location = -1
+				continue
+			}
+			var boundary bool
+			boundary, location = pb.boundaryInside(rings, seg, location)
+			if boundary {
+				return false
+			}
+		}
+		// The polygon's inside is then either entirely inside b or entirely
+		// outside of it, and any of b's edges it contains are shared ones
+		if pb.locate(polygon.interiorPoint()) < 0 {
+			return false
+		}
+		interior = true
+	}
+	return interior
+}
+
+// box is a bounding box in the first two dimensions, the only ones
+// predicates look at, which is cheap enough to check before comparing
+// segments or locating points.
+type box struct {
+	minX, minY, maxX, maxY float64
+}
+
+func emptyBox() box {
+	return box{minX: math.Inf(1), minY: math.Inf(1), maxX: math.Inf(-1), maxY: math.Inf(-1)}
+}
+
+func (b *box) extend(point Point) {
+	x, y := xy(point)
+	b.minX, b.maxX = math.Min(b.minX, x), math.Max(b.maxX, x)
+	b.minY, b.maxY = math.Min(b.minY, y), math.Max(b.maxY, y)
+}
+
+func (b *box) union(other box) {
+	b.minX, b.maxX = math.Min(b.minX, other.minX), math.Max(b.maxX, other.maxX)
+	b.minY, b.maxY = math.Min(b.minY, other.minY), math.Max(b.maxY, other.maxY)
+}
+
+// intersects reports whether the boxes overlap, edges included. Empty boxes
+// intersect nothing.
+func (b box) intersects(other box) bool {
+	return b.minX <= other.maxX && other.minX <= b.maxX && b.minY <= other.maxY && other.minY <= b.maxY
+}
+
+// contains reports whether other lies within the box, edges included.
+func (b box) contains(other box) bool {
+	return b.minX <= other.minX && other.maxX <= b.maxX && b.minY <= other.minY && other.maxY <= b.maxY
+}
+
+func (b box) containsPoint(point Point) bool {
+	x, y := xy(point)
+	return b.minX <= x && x <= b.maxX && b.minY <= y && y <= b.maxY
+}
+
+// segment is an edge of a line or ring, along with its box. First marks
+// the first edge of each line and ring, so that the ones after it can be
+// told to follow on from the edge before them.
+type segment struct {
+	a, b  Point
+	box   box
+	first bool
+}
+
+func newSegment(a, b Point) segment {
+	seg := segment{a: a, b: b, box: emptyBox()}
+	seg.box.extend(a)
+	seg.box.extend(b)
+	return seg
+}
+
+// parts breaks a geometry down into its points, lines and areas. Their
+// segments and boxes are worked out once, up front.
+type parts struct {
+	points   []Point
+	paths    [][]Point
+	polygons []Polygon
+
+	segments     []segment
+	pathBoxes    []box
+	polygonBoxes []box
+	box          box
+}
+
+func partsOf(g Geometry) *parts {
+	p := &parts{box: emptyBox()}
+	p.add(g)
+	for _, point := range p.points {
+		p.box.extend(point)
+	}
+	for _, path := range p.paths {
+		p.pathBoxes = append(p.pathBoxes, p.addSegments(path, false))
+	}
+	for _, polygon := range p.polygons {
+		b := emptyBox()
+		for _, r := range polygon {
+			b.union(p.addSegments(r, true))
+		}
+		p.polygonBoxes = append(p.polygonBoxes, b)
+	}
+	return p
+}
+
+// addSegments adds the segments of the points, returning their box.
+func (p *parts) addSegments(points []Point, closed bool) box {
+	b := emptyBox()
+	for _, point := range points {
+		b.extend(point)
+	}
+	first := len(p.segments)
+	forEachSegment(points, closed, func(a, b Point) {
+		p.segments = append(p.segments, newSegment(a, b))
+	})
+	if first < len(p.segments) {
+		p.segments[first].first = true
+	}
+	p.box.union(b)
+	return b
+}
+
+func (p *parts) add(g Geometry) {
+	switch t := g.(type) {
+	case Point:
+		p.points = append(p.points, t)
+	case MultiPoint:
+		p.points = append(p.points, t...)
+	case LineString:
+		p.addPath(t)
+	case MultiLineString:
+		for _, ls := range t {
+			p.addPath(ls)
+		}
+	case Ring:
+		p.addPolygon(Polygon{t})
+	case Polygon:
+		p.addPolygon(t)
+	case MultiPolygon:
+		for _, polygon := range t {
+			p.addPolygon(polygon)
+		}
+	case Collection:
+		for _, child := range t {
+			p.add(child)
+		}
+	}
+}
+
+// addPath adds a line, or a point if it only has one.
+func (p *parts) addPath(points []Point) {
+	switch len(points) {
+	case 0:
+	case 1:
+		p.points = append(p.points, points[0])
+	default:
+		p.paths = append(p.paths, points)
+	}
+}
+
+func (p *parts) addPolygon(polygon Polygon) {
+	if len(polygon) > 0 && len(polygon[0]) > 0 {
+		p.polygons = append(p.polygons, polygon)
+	}
+}
+
+// representatives returns the points, along with a point of each line and
+// area.
+func (p *parts) representatives() []Point {
+	points := make([]Point, 0, len(p.points)+len(p.paths)+len(p.polygons))
+	points = append(points, p.points...)
+	for _, path := range p.paths {
+		points = append(points, path[0])
+	}
+	for _, polygon := range p.polygons {
+		points = append(points, polygon[0][0])
+	}
+	return points
+}
+
+// locate is 1 if point lies inside any of the parts, 0 if it only lies on
+// their boundary and -1 if it lies outside of all of them.
+func (p *parts) locate(point Point) int {
+	if !p.box.containsPoint(point) {
+		return -1
+	}
+	location := -1
+	for _, other := range p.points {
+		if samePosition(other, point) {
+			return 1
+		}
+	}
+	for i, path := range p.paths {
+		if !p.pathBoxes[i].containsPoint(point) {
+			continue
+		}
+		closed := samePosition(path[0], path[len(path)-1])
+		if !closed && (samePosition(path[0], point) || samePosition(path[len(path)-1], point)) {
+			location = 0
+			continue
+		}
+		for i := 1; i < len(path); i++ {
+			if OnSegment(point, path[i-1], path[i]) {
+				return 1
+			}
+		}
+	}
+	for i, polygon := range p.polygons {
+		if !p.polygonBoxes[i].containsPoint(point) {
+			continue
+		}
+		switch polygon.locate(point) {
+		case 1:
+			return 1
+		case 0:
+			location = 0
+		}
+	}
+	return location
+}
+
+// covers reports whether the segment lies within the parts, and whether
+// any of it lies inside them rather than on their boundary. The segment is
+// split wherever it meets the edges of the parts, and each piece is judged
+// by its middle.
+//
+// A segment that meets nothing of the parts lies entirely inside or outside
+// of them, as does the segment that follows it if that meets nothing
+// either. Covers returns where such a segment lies, 1 or -1, to be passed
+// back as previous for the segment after it, and 0 otherwise.
+func (p *parts) covers(seg segment, previous int) (bool, bool, int) {
+	a, b := seg.a, seg.b
+	if samePosition(a, b) {
+		location := p.locate(a)
+		return location >= 0, location > 0, 0
+	}
+
+	splits := []float64{0, 1}
+	for _, edge := range p.segments {
+		if edge.box.intersects(seg.box) {
+			splits = append(splits, crossings(a, b, edge.a, edge.b)...)
+		}
+	}
+	for _, point := range p.points {
+		if OnSegment(point, a, b) {
+			splits = append(splits, projection(a, b, point))
+		}
+	}
+	if len(splits) == 2 {
+		if previous == 0 {
+			previous = p.locate(along(a, b, 0.5))
+		}
+		return previous > 0, previous > 0, previous
+	}
+	sort.Float64s(splits)
+
+	inside := false
+	for i, t := range splits {
+		location := p.locate(along(a, b, t))
+		if i > 0 && t > splits[i-1] {
+			middle := p.locate(along(a, b, (splits[i-1]+t)/2))
+			if middle < 0 {
+				return false, false, 0
+			}
+			inside = inside || middle > 0
+		}
+		if location < 0 {
+			return false, false, 0
+		}
+	}
+	return true, inside, 0
+}
+
+// boundaryInside reports whether any of the segment that lies inside the
+// polygon is part of the boundary of the parts, rather than an edge with
+// the parts on both sides of it. The segment is split wherever it meets
+// the polygon's rings, and each piece is judged by its middle. Like covers,
+// it takes and returns where segments that don't meet the rings lie.
+func (p *parts) boundaryInside(polygon *parts, seg segment, previous int) (bool, int) {
+	a, b := seg.a, seg.b
+	if samePosition(a, b) {
+		return false, 0
+	}
+	splits := []float64{0, 1}
+	for _, edge := range polygon.segments {
+		if edge.box.intersects(seg.box) {
+			splits = append(splits, crossings(a, b, edge.a, edge.b)...)
+		}
+	}
+	if len(splits) == 2 {
+		middle := along(a, b, 0.5)
+		if previous == 0 {
+			previous = polygon.locate(middle)
+		}
+		return previous > 0 && !p.surrounds(a, b, middle), previous
+	}
+	sort.Float64s(splits)
+
+	for i := 1; i < len(splits); i++ {
+		if splits[i] == splits[i-1] {
+			continue
+		}
+		middle := along(a, b, (splits[i-1]+splits[i])/2)
+		if polygon.locate(middle) > 0 && !p.surrounds(a, b, middle) {
+			return true, 0
+		}
+	}
+	return false, 0
+}
+
+// surrounds reports whether the parts lie on both sides of the segment a-b
+// just off point, which lies on it.
+func (p *parts) surrounds(a, b, point Point) bool {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	px, py := xy(point)
+	// Step off the segment by a tiny fraction of its length either way
+	nx, ny := (ay-by)*1e-9, (bx-ax)*1e-9
+	return p.locate(Point{px + nx, py + ny}) > 0 && p.locate(Point{px - nx, py - ny}) > 0
+}
+
+// interiorPoint returns a point inside the polygon and away from its rings:
+// the middle of the widest stretch of the polygon along a horizontal line
+// that passes through none of its points.
+func (p Polygon) interiorPoint() Point {
+	var ys []float64
+	for _, r := range p {
+		for _, point := range r {
+			_, y := xy(point)
+			ys = append(ys, y)
+		}
+	}
+	sort.Float64s(ys)
+	y, gap := 0.0, 0.0
+	for i := 1; i < len(ys); i++ {
+		if ys[i]-ys[i-1] > gap {
+			y, gap = (ys[i-1]+ys[i])/2, ys[i]-ys[i-1]
+		}
+	}
+
+	var xs []float64
+	for _, r := range p {
+		forEachSegment(r, true, func(a, b Point) {
+			ax, ay := xy(a)
+			bx, by := xy(b)
+			if (ay > y) != (by > y) {
+				xs = append(xs, ax+(y-ay)*(bx-ax)/(by-ay))
+			}
+		})
+	}
+	sort.Float64s(xs)
+	// The line enters the polygon at every other crossing
+	x, width := 0.0, -1.0
+	for i := 1; i < len(xs); i += 2 {
+		if xs[i]-xs[i-1] > width {
+			x, width = (xs[i-1]+xs[i])/2, xs[i]-xs[i-1]
+		}
+	}
+	if width < 0 {
+		return p[0][0]
+	}
+	return Point{x, y}
+}
+
+// along returns the point at t along a-b, from 0 at a to 1 at b.
+func along(a, b Point, t float64) Point {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	return Point{ax + (bx-ax)*t, ay + (by-ay)*t}
+}
+
+// crossings returns where along a-b the segment c-d meets it, from 0 at a
+// to 1 at b: once where they cross, and at both ends of where they overlap.
+func crossings(a, b, c, d Point) []float64 {
+	if !SegmentsIntersect(a, b, c, d) {
+		return nil
+	}
+	if Orientation(a, b, c) == 0 && Orientation(a, b, d) == 0 {
+		var ts []float64
+		for _, point := range []Point{c, d} {
+			if OnSegment(point, a, b) {
+				ts = append(ts, projection(a, b, point))
+			}
+		}
+		return ts
+	}
+
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	cx, cy := xy(c)
+	dx, dy := xy(d)
+	denominator := (bx-ax)*(dy-cy) - (by-ay)*(dx-cx)
+	return []float64{((cx-ax)*(dy-cy) - (cy-ay)*(dx-cx)) / denominator}
+}
+
+// projection returns where along a-b the point closest to point lies.
+func projection(a, b, point Point) float64 {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	px, py := xy(point)
+	return ((px-ax)*(bx-ax) + (py-ay)*(by-ay)) / ((bx-ax)*(bx-ax) + (by-ay)*(by-ay))
+}
+
+// SegmentsIntersect reports whether the segments a-b and c-d have any point
+// in common.
+func SegmentsIntersect(a, b, c, d Point) bool {
+	o1 := Orientation(a, b, c)
+	o2 := Orientation(a, b, d)
+	o3 := Orientation(c, d, a)
+	o4 := Orientation(c, d, b)
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+	return (o1 == 0 && OnSegment(c, a, b)) ||
+		(o2 == 0 && OnSegment(d, a, b)) ||
+		(o3 == 0 && OnSegment(a, c, d)) ||
+		(o4 == 0 && OnSegment(b, c, d))
+}
+
+// orientationBound is the relative error bound of the floating point cross
+// product in orientation, from Shewchuk's "Adaptive Precision
+// Floating-Point Arithmetic and Fast Robust Geometric Predicates".
+const orientationBound = (3 + 16*0x1p-53) * 0x1p-53
+
+// Orientation is 1 if c lies left of the line through a and b, -1 if it
+// lies right of it and 0 if the three points are collinear. The answer is
+// exact: whenever rounding could have flipped the sign of the floating
+// point cross product, it's worked out again with rationals.
+func Orientation(a, b, c Point) int {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	cx, cy := xy(c)
+	left := (bx - ax) * (cy - ay)
+	right := (by - ay) * (cx - ax)
+	cross := left - right
+	if bound := orientationBound * (math.Abs(left) + math.Abs(right)); cross > bound || -cross > bound {
+		return sign(cross)
+	}
+	return exactOrientation(cross, ax, ay, bx, by, cx, cy)
+}
+
+// exactOrientation is the sign of (bx-ax)(cy-ay) - (by-ay)(cx-ax) worked
+// out without rounding. Infinities and NaNs can't be, so they keep the sign
+// of the floating point cross product.
+func exactOrientation(cross float64, coords ...float64) int {
+	rats := make([]*big.Rat, len(coords))
+	for i, c := range coords {
+		if rats[i] = new(big.Rat).SetFloat64(c); rats[i] == nil {
+			return sign(cross)
+		}
+	}
+	ax, ay, bx, by, cx, cy := rats[0], rats[1], rats[2], rats[3], rats[4], rats[5]
+	left := new(big.Rat).Mul(new(big.Rat).Sub(bx, ax), new(big.Rat).Sub(cy, ay))
+	right := new(big.Rat).Mul(new(big.Rat).Sub(by, ay), new(big.Rat).Sub(cx, ax))
+	return left.Cmp(right)
+}
+
+func sign(x float64) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// OnSegment reports whether point lies on the segment a-b.
+func OnSegment(point, a, b Point) bool {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	px, py := xy(point)
+	return math.Min(ax, bx) <= px && px <= math.Max(ax, bx) &&
+		math.Min(ay, by) <= py && py <= math.Max(ay, by) &&
+		Orientation(a, b, point) == 0
+}
+
+func samePosition(a, b Point) bool {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	return ax == bx && ay == by
+}
@@ -0,0 +1,194 @@
+package geometry_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestPolygonContains(t *testing.T) {
+	testCases := []struct {
+		Point    Point
+		Expected bool
+	}{
+		{Point: Point{1, 1}, Expected: true},
+		{Point: Point{9.999, 5}, Expected: true},
+		// Inside the hole
+		{Point: Point{3, 3}, Expected: false},
+		// On the exterior ring, at an edge and at a vertex
+		{Point: Point{5, 0}, Expected: false},
+		{Point: Point{10, 10}, Expected: false},
+		// On the hole, at an edge and at a vertex
+		{Point: Point{2, 3}, Expected: false},
+		{Point: Point{4, 4}, Expected: false},
+		// Level with a vertex, which mustn't be counted twice
+		{Point: Point{1, 2}, Expected: true},
+		{Point: Point{-1, 0}, Expected: false},
+		{Point: Point{-1, 10}, Expected: false},
+		{Point: Point{11, 5}, Expected: false},
+		// Extra dimensions don't matter
+		{Point: Point{1, 1, 100}, Expected: true},
+	}
+
+	for i, test := range testCases {
+		if got := donut.Contains(test.Point); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t for %v, got %t", i, test.Expected, test.Point, got)
+		}
+		if got := (MultiPolygon{{{{20, 20}, {21, 20}, {21, 21}, {20, 20}}}, donut}).Contains(test.Point); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t for %v in a multi polygon, got %t", i, test.Expected, test.Point, got)
+		}
+		if got := Within(test.Point, donut); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t for %v within, got %t", i, test.Expected, test.Point, got)
+		}
+	}
+}
+
+func TestRingContains(t *testing.T) {
+	// A concave ring, shaped like a U
+	ring := Ring{{0, 0}, {3, 0}, {3, 3}, {2, 3}, {2, 1}, {1, 1}, {1, 3}, {0, 3}, {0, 0}}
+	testCases := []struct {
+		Point    Point
+		Expected bool
+	}{
+		{Point: Point{0.5, 2}, Expected: true},
+		{Point: Point{1.5, 2}, Expected: false},
+		{Point: Point{1.5, 1}, Expected: false},
+		{Point: Point{1.5, 0.5}, Expected: true},
+		{Point: Point{2.5, 3}, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := ring.Contains(test.Point); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t for %v, got %t", i, test.Expected, test.Point, got)
+		}
+	}
+}
+
+func TestIntersects(t *testing.T) {
+	square := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}
+	testCases := []struct {
+		A        Geometry
+		B        Geometry
+		Expected bool
+	}{
+		{A: Point{1, 1}, B: Point{1, 1}, Expected: true},
+		{A: Point{1, 1}, B: Point{1, 2}, Expected: false},
+		{A: Point{1, 1}, B: LineString{{0, 0}, {2, 2}}, Expected: true},
+		{A: Point{2, 2}, B: LineString{{0, 0}, {2, 2}}, Expected: true},
+		{A: Point{1, 1.5}, B: LineString{{0, 0}, {2, 2}}, Expected: false},
+		// Just off the line, where the floating point cross product rounds to 0
+		{A: Point{12, 12}, B: LineString{{0.5, 0.5000000000000001}, {24, 24}}, Expected: false},
+		{A: Point{1, 1}, B: square, Expected: true},
+		{A: Point{2, 1}, B: square, Expected: true},
+		{A: Point{2, 2}, B: square, Expected: true},
+		{A: Point{3, 1}, B: square, Expected: false},
+		{A: Point{3, 3}, B: donut, Expected: false},
+		{A: MultiPoint{{5, 5}, {1, 1}}, B: square, Expected: true},
+		{A: LineString{{0, 2}, {2, 0}}, B: LineString{{0, 0}, {2, 2}}, Expected: true},
+		{A: LineString{{0, 1}, {1, 2}}, B: LineString{{0, 0}, {2, 2}}, Expected: false},
+		// Touching at their ends, and overlapping along a line
+		{A: LineString{{2, 2}, {3, 3}}, B: LineString{{0, 0}, {2, 2}}, Expected: true},
+		{A: LineString{{1, 1}, {3, 3}}, B: LineString{{0, 0}, {2, 2}}, Expected: true},
+		{A: LineString{{-1, 1}, {3, 1}}, B: square, Expected: true},
+		// Inside without touching the edges
+		{A: LineString{{0.5, 0.5}, {1.5, 1.5}}, B: square, Expected: true},
+		{A: LineString{{2.5, 3}, {3.5, 2}}, B: square, Expected: false},
+		{A: LineString{{2.5, 2.5}, {3.5, 3.5}}, B: donut, Expected: false},
+		{A: LineString{{2.5, 2.5}, {5, 5}}, B: donut, Expected: true},
+		{A: square, B: Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}, Expected: true},
+		{A: square, B: Polygon{{{2, 0}, {3, 0}, {3, 2}, {2, 2}, {2, 0}}}, Expected: true},
+		{A: square, B: Polygon{{{3, 0}, {4, 0}, {4, 2}, {3, 2}, {3, 0}}}, Expected: false},
+		// One inside the other, and inside a hole
+		{A: Polygon{{{0.5, 0.5}, {1, 0.5}, {1, 1}, {0.5, 0.5}}}, B: square, Expected: true},
+		{A: Polygon{{{2.5, 2.5}, {3, 2.5}, {3, 3}, {2.5, 2.5}}}, B: donut, Expected: false},
+		{A: Collection{Point{20, 20}, LineString{{1, -1}, {1, 1}}}, B: MultiPolygon{square}, Expected: true},
+		{A: nil, B: square, Expected: false},
+		{A: Collection{}, B: square, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := Intersects(test.A, test.B); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+		if got := Intersects(test.B, test.A); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t reversed, got %t", i, test.Expected, got)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	square := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}
+	testCases := []struct {
+		A        Geometry
+		B        Geometry
+		Expected bool
+	}{
+		{A: Point{1, 1}, B: Point{1, 1}, Expected: true},
+		{A: Point{1, 1}, B: LineString{{0, 0}, {2, 2}}, Expected: true},
+		// The ends of a line are its boundary
+		{A: Point{0, 0}, B: LineString{{0, 0}, {2, 2}}, Expected: false},
+		{A: Point{0, 0}, B: Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, Expected: false},
+		{A: MultiPoint{{1, 1}, {2, 2}}, B: square, Expected: true},
+		{A: MultiPoint{{2, 0}, {2, 2}}, B: square, Expected: false},
+		{A: MultiPoint{{1, 1}, {3, 3}}, B: square, Expected: false},
+		{A: LineString{{0.5, 0.5}, {1.5, 1.5}}, B: square, Expected: true},
+		{A: LineString{{1, 1}, {2, 2}}, B: square, Expected: true},
+		{A: LineString{{0, 0}, {2, 0}}, B: square, Expected: false},
+		{A: LineString{{1, 1}, {3, 3}}, B: square, Expected: false},
+		// Leaving through a vertex and coming back in
+		{A: LineString{{1, 1}, {3, 3}, {1, 1.5}}, B: square, Expected: false},
+		// Crossing a hole with both ends inside the polygon
+		{A: LineString{{1, 3}, {5, 3}}, B: donut, Expected: false},
+		{A: LineString{{1, 1}, {2, 2}}, B: LineString{{0, 0}, {3, 3}}, Expected: true},
+		{A: LineString{{1, 1}, {2, 2}}, B: MultiLineString{{{0, 0}, {1.5, 1.5}}, {{1.5, 1.5}, {3, 3}}}, Expected: true},
+		{A: LineString{{1, 1}, {2, 2.5}}, B: LineString{{0, 0}, {3, 3}}, Expected: false},
+		// Running through two neighbouring polygons
+		{A: LineString{{1, 1}, {3, 1}}, B: MultiPolygon{square, {{{2, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 0}}}}, Expected: true},
+		{A: square, B: square, Expected: true},
+		{A: Polygon{{{0.5, 0.5}, {1, 0.5}, {1, 1}, {0.5, 0.5}}}, B: square, Expected: true},
+		{A: square, B: Polygon{{{0.5, 0.5}, {1, 0.5}, {1, 1}, {0.5, 0.5}}}, Expected: false},
+		{A: Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}, B: square, Expected: false},
+		{A: Polygon{{{1, 1}, {9, 1}, {9, 9}, {1, 9}, {1, 1}}}, B: donut, Expected: false},
+		{A: Polygon{{{5, 5}, {9, 5}, {9, 9}, {5, 9}, {5, 5}}}, B: donut, Expected: true},
+		{A: square, B: LineString{{0, 0}, {2, 2}}, Expected: false},
+		// Exactly filling a hole lies outside of the polygon
+		{A: Polygon{{{2, 2}, {8, 2}, {8, 8}, {2, 8}, {2, 2}}}, B: Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, {{2, 2}, {8, 2}, {8, 8}, {2, 8}, {2, 2}}}, Expected: false},
+		// Spanning two neighbouring polygons, across the edge they share
+		{A: Polygon{{{1, 0.5}, {3, 0.5}, {3, 1.5}, {1, 1.5}, {1, 0.5}}}, B: MultiPolygon{square, {{{2, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 0}}}}, Expected: true},
+		{A: Polygon{{{1, 0.5}, {3, 0.5}, {3, 1.5}, {1, 1.5}, {1, 0.5}}}, B: MultiPolygon{square, {{{2, 0}, {4, 0}, {4, 1}, {2, 1}, {2, 0}}}}, Expected: false},
+		{A: Collection{Point{1, 1}, LineString{{0, 0}, {1, 1}}}, B: Collection{square}, Expected: true},
+		{A: nil, B: square, Expected: false},
+		{A: Collection{}, B: square, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := Within(test.A, test.B); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+	}
+}
+
+// circle returns a ring of n points around the origin.
+func circle(n int, radius float64) Ring {
+	ring := make(Ring, n+1)
+	for i := range ring {
+		angle := 2 * math.Pi * float64(i%n) / float64(n)
+		ring[i] = Point{radius * math.Cos(angle), radius * math.Sin(angle)}
+	}
+	return ring
+}
+
+func BenchmarkWithin(b *testing.B) {
+	for _, n := range []int{100, 200, 400} {
+		inner, outer := Polygon{circle(n, 1)}, Polygon{circle(n, 2)}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !Within(inner, outer) {
+					b.Fatalf("Expected %d points to be within", n)
+				}
+			}
+		})
+	}
+}
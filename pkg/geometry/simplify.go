@@ -0,0 +1,245 @@
+package geometry
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Simplify returns the line with the points that lie within tolerance of
+// it removed, using the Ramer-Douglas-Peucker algorithm. The tolerance is
+// a distance in the units of the first two dimensions. The first and last
+// points are always kept.
+func (ls LineString) Simplify(tolerance float64) LineString {
+	return LineString(douglasPeucker(ls, tolerance))
+}
+
+// SimplifyVW returns the line with the points that add less than area to
+// it removed, using the Visvalingam-Whyatt algorithm. The first and last
+// points are always kept.
+func (ls LineString) SimplifyVW(area float64) LineString {
+	return LineString(visvalingam(ls, area))
+}
+
+func (m MultiLineString) Simplify(tolerance float64) MultiLineString {
+	simplified := make(MultiLineString, len(m))
+	for i, ls := range m {
+		simplified[i] = ls.Simplify(tolerance)
+	}
+	return simplified
+}
+
+func (m MultiLineString) SimplifyVW(area float64) MultiLineString {
+	simplified := make(MultiLineString, len(m))
+	for i, ls := range m {
+		simplified[i] = ls.SimplifyVW(area)
+	}
+	return simplified
+}
+
+// Simplify is LineString.Simplify for rings. A ring that would be left with
+// fewer than four points is returned as it is, so that it stays a ring.
+// Simplifying can still make rings cross themselves or each other.
+func (r Ring) Simplify(tolerance float64) Ring {
+	return r.simplified(douglasPeucker(r, tolerance))
+}
+
+// SimplifyVW is LineString.SimplifyVW for rings, which keeps rings that
+// would be left with fewer than four points as they are.
+func (r Ring) SimplifyVW(area float64) Ring {
+	return r.simplified(visvalingam(r, area))
+}
+
+func (r Ring) simplified(points []Point) Ring {
+	if len(points) < 4 {
+		return r
+	}
+	return Ring(points)
+}
+
+func (p Polygon) Simplify(tolerance float64) Polygon {
+	simplified := make(Polygon, len(p))
+	for i, r := range p {
+		simplified[i] = r.Simplify(tolerance)
+	}
+	return simplified
+}
+
+func (p Polygon) SimplifyVW(area float64) Polygon {
+	simplified := make(Polygon, len(p))
+	for i, r := range p {
+		simplified[i] = r.SimplifyVW(area)
+	}
+	return simplified
+}
+
+func (mp MultiPolygon) Simplify(tolerance float64) MultiPolygon {
+	simplified := make(MultiPolygon, len(mp))
+	for i, p := range mp {
+		simplified[i] = p.Simplify(tolerance)
+	}
+	return simplified
+}
+
+func (mp MultiPolygon) SimplifyVW(area float64) MultiPolygon {
+	simplified := make(MultiPolygon, len(mp))
+	for i, p := range mp {
+		simplified[i] = p.SimplifyVW(area)
+	}
+	return simplified
+}
+
+// xy returns the first two coordinates of a point, which are 0 if it
+// doesn't have them.
+func xy(p Point) (float64, float64) {
+	switch len(p) {
+	case 0:
+		return 0, 0
+	case 1:
+		return p[0], 0
+	}
+	return p[0], p[1]
+}
+
+// douglasPeucker keeps the point furthest from the line between two kept
+// points, as long as it's further than tolerance, starting out with the
+// first and last points. Spans still to be split are kept on a stack
+// rather than recursing, so long lines can't overflow the call stack.
+func douglasPeucker(points []Point, tolerance float64) []Point {
+	if len(points) < 3 {
+		return points
+	}
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	sqTolerance := tolerance * tolerance
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		furthest, sqDistance := 0, sqTolerance
+		for i := first + 1; i < last; i++ {
+			if d := sqSegmentDistance(points[i], points[first], points[last]); d > sqDistance {
+				furthest, sqDistance = i, d
+			}
+		}
+		if furthest > 0 {
+			keep[furthest] = true
+			stack = append(stack, [2]int{first, furthest}, [2]int{furthest, last})
+		}
+	}
+	return kept(points, keep)
+}
+
+// sqSegmentDistance is the squared distance from p to the segment a-b.
+func sqSegmentDistance(p, a, b Point) float64 {
+	x, y := xy(a)
+	bx, by := xy(b)
+	px, py := xy(p)
+	dx, dy := bx-x, by-y
+	if dx != 0 || dy != 0 {
+		t := ((px-x)*dx + (py-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = bx, by
+		} else if t > 0 {
+			x, y = x+dx*t, y+dy*t
+		}
+	}
+	dx, dy = px-x, py-y
+	return dx*dx + dy*dy
+}
+
+// visvalingam repeatedly removes the point forming the smallest triangle
+// with its neighbours, until every triangle left is at least area. A point
+// whose triangle grows smaller once a neighbour is removed keeps the area
+// of the removed one, so points are removed in a stable order.
+func visvalingam(points []Point, area float64) []Point {
+	if len(points) < 3 {
+		return points
+	}
+	n := len(points)
+	prev, next := make([]int, n), make([]int, n)
+	h := &areaHeap{areas: make([]float64, n), positions: make([]int, n)}
+	for i := range points {
+		prev[i], next[i] = i-1, i+1
+		if i > 0 && i < n-1 {
+			h.areas[i] = triangleArea(points[i-1], points[i], points[i+1])
+			h.positions[i] = len(h.indexes)
+			h.indexes = append(h.indexes, i)
+		}
+	}
+	heap.Init(h)
+
+	keep := make([]bool, n)
+	for i := range keep {
+		keep[i] = true
+	}
+	for h.Len() > 0 {
+		i := heap.Pop(h).(int)
+		removed := h.areas[i]
+		if removed >= area {
+			break
+		}
+		keep[i] = false
+		before, after := prev[i], next[i]
+		next[before], prev[after] = after, before
+		for _, j := range [2]int{before, after} {
+			if j == 0 || j == n-1 {
+				continue
+			}
+			h.areas[j] = math.Max(triangleArea(points[prev[j]], points[j], points[next[j]]), removed)
+			heap.Fix(h, h.positions[j])
+		}
+	}
+	return kept(points, keep)
+}
+
+func triangleArea(a, b, c Point) float64 {
+	ax, ay := xy(a)
+	bx, by := xy(b)
+	cx, cy := xy(c)
+	return math.Abs((bx-ax)*(cy-ay)-(cx-ax)*(by-ay)) / 2
+}
+
+func kept(points []Point, keep []bool) []Point {
+	simplified := make([]Point, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// areaHeap orders point indexes by the area of their triangle, keeping
+// track of where each index is so its area can be updated.
+type areaHeap struct {
+	indexes   []int
+	areas     []float64
+	positions []int
+}
+
+func (h *areaHeap) Len() int {
+	return len(h.indexes)
+}
+
+func (h *areaHeap) Less(i, j int) bool {
+	return h.areas[h.indexes[i]] < h.areas[h.indexes[j]]
+}
+
+func (h *areaHeap) Swap(i, j int) {
+	h.indexes[i], h.indexes[j] = h.indexes[j], h.indexes[i]
+	h.positions[h.indexes[i]] = i
+	h.positions[h.indexes[j]] = j
+}
+
+func (h *areaHeap) Push(x interface{}) {
+	h.positions[x.(int)] = len(h.indexes)
+	h.indexes = append(h.indexes, x.(int))
+}
+
+func (h *areaHeap) Pop() interface{} {
+	last := h.indexes[len(h.indexes)-1]
+	h.indexes = h.indexes[:len(h.indexes)-1]
+	return last
+}
@@ -0,0 +1,68 @@
+package geometry_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestLineStringSimplify(t *testing.T) {
+	line := LineString{{0, 0}, {1, 0.1}, {2, -0.1}, {3, 5}, {4, 6}, {5, 7}, {6, 8.1}, {7, 9}, {8, 9}, {9, 9}}
+	testCases := []struct {
+		Tolerance float64
+		Expected  LineString
+	}{
+		// Only points exactly on the line go
+		{Tolerance: 0, Expected: LineString{{0, 0}, {1, 0.1}, {2, -0.1}, {3, 5}, {5, 7}, {6, 8.1}, {7, 9}, {9, 9}}},
+		{Tolerance: 0.5, Expected: LineString{{0, 0}, {2, -0.1}, {3, 5}, {7, 9}, {9, 9}}},
+		{Tolerance: 100, Expected: LineString{{0, 0}, {9, 9}}},
+	}
+
+	for i, test := range testCases {
+		if got := line.Simplify(test.Tolerance); !reflect.DeepEqual(test.Expected, got) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestLineStringSimplifyVW(t *testing.T) {
+	line := LineString{{0, 0}, {1, 0.1}, {2, 0}, {3, 3}, {4, 0}, {5, 0}}
+	testCases := []struct {
+		Area     float64
+		Expected LineString
+	}{
+		{Area: 0, Expected: line},
+		{Area: 0.5, Expected: LineString{{0, 0}, {2, 0}, {3, 3}, {4, 0}, {5, 0}}},
+		{Area: 3.5, Expected: LineString{{0, 0}, {3, 3}, {5, 0}}},
+		{Area: 100, Expected: LineString{{0, 0}, {5, 0}}},
+	}
+
+	for i, test := range testCases {
+		if got := line.SimplifyVW(test.Area); !reflect.DeepEqual(test.Expected, got) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestPolygonSimplify(t *testing.T) {
+	polygon := Polygon{
+		{{0, 0}, {5, 0.1}, {10, 0}, {10, 10}, {5, 10.1}, {0, 10}, {0, 0}},
+		// Too small to survive as a ring
+		{{4, 4}, {4.1, 4}, {4.1, 4.1}, {4, 4}},
+	}
+	expected := Polygon{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {4.1, 4}, {4.1, 4.1}, {4, 4}},
+	}
+
+	if got := polygon.Simplify(1); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := polygon.SimplifyVW(1); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := (MultiPolygon{polygon}).Simplify(1); !reflect.DeepEqual(MultiPolygon{expected}, got) {
+		t.Errorf("Expected %v, got %v", MultiPolygon{expected}, got)
+	}
+}
@@ -0,0 +1,414 @@
+package geometry
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the earth in metres, which spherical
+// measures take the earth's radius to be.
+const EarthRadius = 6371008.8
+
+// Spherical measures take the first two dimensions of points to be the
+// longitude and latitude in degrees, and connect them along great circles.
+// They return metres and square metres. Whether a point lies inside an area
+// is still worked out in longitude and latitude, so areas crossing the
+// antimeridian or covering a pole aren't supported.
+
+// vector is a point on the unit sphere.
+type vector [3]float64
+
+func toVector(p Point) vector {
+	lon, lat := xy(p)
+	lon, lat = lon*math.Pi/180, lat*math.Pi/180
+	return vector{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+func (v vector) point() Point {
+	return Point{
+		math.Atan2(v[1], v[0]) * 180 / math.Pi,
+		math.Atan2(v[2], math.Hypot(v[0], v[1])) * 180 / math.Pi,
+	}
+}
+
+func (v vector) add(w vector) vector {
+	return vector{v[0] + w[0], v[1] + w[1], v[2] + w[2]}
+}
+
+func (v vector) sub(w vector) vector {
+	return vector{v[0] - w[0], v[1] - w[1], v[2] - w[2]}
+}
+
+func (v vector) scale(s float64) vector {
+	return vector{v[0] * s, v[1] * s, v[2] * s}
+}
+
+func (v vector) dot(w vector) float64 {
+	return v[0]*w[0] + v[1]*w[1] + v[2]*w[2]
+}
+
+func (v vector) cross(w vector) vector {
+	return vector{v[1]*w[2] - v[2]*w[1], v[2]*w[0] - v[0]*w[2], v[0]*w[1] - v[1]*w[0]}
+}
+
+func (v vector) norm() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+// angle returns the angle between two points, which is their distance on
+// the unit sphere.
+func angle(a, b vector) float64 {
+	return math.Atan2(a.cross(b).norm(), a.dot(b))
+}
+
+// SphericalLength returns the length of the line in metres.
+func (ls LineString) SphericalLength() float64 {
+	return sphericalPathLength(ls, false)
+}
+
+func (m MultiLineString) SphericalLength() float64 {
+	sum := 0.0
+	for _, ls := range m {
+		sum += ls.SphericalLength()
+	}
+	return sum
+}
+
+// SphericalLength returns the perimeter of the ring in metres.
+func (r Ring) SphericalLength() float64 {
+	return sphericalPathLength(r, true)
+}
+
+// SphericalLength returns the perimeter of the polygon in metres, holes
+// included.
+func (p Polygon) SphericalLength() float64 {
+	sum := 0.0
+	for _, r := range p {
+		sum += r.SphericalLength()
+	}
+	return sum
+}
+
+func (mp MultiPolygon) SphericalLength() float64 {
+	sum := 0.0
+	for _, p := range mp {
+		sum += p.SphericalLength()
+	}
+	return sum
+}
+
+func (c Collection) SphericalLength() float64 {
+	sum := 0.0
+	for _, g := range c {
+		if l, ok := g.(interface{ SphericalLength() float64 }); ok {
+			sum += l.SphericalLength()
+		}
+	}
+	return sum
+}
+
+func sphericalPathLength(points []Point, closed bool) float64 {
+	sum := 0.0
+	forEachSegment(points, closed, func(a, b Point) {
+		sum += angle(toVector(a), toVector(b))
+	})
+	return sum * EarthRadius
+}
+
+// SphericalArea returns the area the ring encloses in square metres.
+func (r Ring) SphericalArea() float64 {
+	return math.Abs(r.sphericalSignedArea()) * EarthRadius * EarthRadius
+}
+
+// sphericalSignedArea returns the area of the ring on the unit sphere,
+// positive if it winds counter-clockwise. It sums up the spherical excess of
+// the triangles fanning out from the ring's first point.
+func (r Ring) sphericalSignedArea() float64 {
+	if len(r) < 3 {
+		return 0
+	}
+	origin := toVector(r[0])
+	sum := 0.0
+	forEachSegment(r[1:], false, func(b, c Point) {
+		sum += sphericalExcess(origin, toVector(b), toVector(c))
+	})
+	return sum
+}
+
+// sphericalExcess returns the signed area of the triangle abc on the unit
+// sphere, using the formula of Van Oosterom and Strackee. The triple product
+// is taken of the edges leaving a, which keeps it accurate for the small
+// triangles of small rings.
+func sphericalExcess(a, b, c vector) float64 {
+	det := a.dot(b.sub(a).cross(c.sub(a)))
+	return 2 * math.Atan2(det, 1+a.dot(b)+b.dot(c)+c.dot(a))
+}
+
+// SphericalArea returns the area of the polygon in square metres, which is
+// that of the exterior ring less that of the holes.
+func (p Polygon) SphericalArea() float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	area := p[0].SphericalArea()
+	for _, hole := range p[1:] {
+		area -= hole.SphericalArea()
+	}
+	return area
+}
+
+func (mp MultiPolygon) SphericalArea() float64 {
+	sum := 0.0
+	for _, p := range mp {
+		sum += p.SphericalArea()
+	}
+	return sum
+}
+
+func (c Collection) SphericalArea() float64 {
+	sum := 0.0
+	for _, g := range c {
+		if a, ok := g.(interface{ SphericalArea() float64 }); ok {
+			sum += a.SphericalArea()
+		}
+	}
+	return sum
+}
+
+// sphericalCentroid is centroid on the unit sphere: it sums up the first
+// moments of points, lines and areas, whose direction is the centroid.
+type sphericalCentroid struct {
+	dimension int
+	moment    vector
+	weight    float64
+}
+
+func (c *sphericalCentroid) add(dimension int, moment vector, weight float64) {
+	if dimension < c.dimension {
+		return
+	}
+	if dimension > c.dimension {
+		*c = sphericalCentroid{dimension: dimension}
+	}
+	c.moment = c.moment.add(moment)
+	c.weight += weight
+}
+
+// point returns nil if nothing was added, or if the moments cancelled each
+// other out as far as rounding lets them.
+func (c *sphericalCentroid) point() Point {
+	if c.weight == 0 || c.moment.norm() < 1e-9*c.weight {
+		return nil
+	}
+	return c.moment.point()
+}
+
+func (c *sphericalCentroid) addPoints(points []Point) {
+	for _, p := range points {
+		c.add(0, toVector(p), 1)
+	}
+}
+
+// addPath adds the moment of each great circle arc, which points to the
+// arc's middle and is 2·sin(θ/2) long for an arc of angle θ.
+func (c *sphericalCentroid) addPath(points []Point, closed bool) {
+	if sphericalPathLength(points, closed) == 0 {
+		c.addPoints(points)
+		return
+	}
+	forEachSegment(points, closed, func(a, b Point) {
+		va, vb := toVector(a), toVector(b)
+		theta := angle(va, vb)
+		middle := va.add(vb)
+		if n := middle.norm(); n > 0 {
+			c.add(1, middle.scale(2*math.Sin(theta/2)/n), theta)
+		}
+	})
+}
+
+// addPolygon adds the moment of the polygon's area. By Stokes' theorem,
+// that's half the sum of the normals of its counter-clockwise edges, each
+// scaled by the edge's angle. Rewinding the polygon makes holes wind
+// clockwise so they take their moment away.
+func (c *sphericalCentroid) addPolygon(p Polygon) {
+	if p.Area() == 0 {
+		for _, r := range p {
+			c.addPath(r, true)
+		}
+		return
+	}
+	moment := vector{}
+	for _, r := range p.Rewind(true) {
+		forEachSegment(r, true, func(a, b Point) {
+			va, vb := toVector(a), toVector(b)
+			normal := va.cross(vb)
+			if n := normal.norm(); n > 0 {
+				moment = moment.add(normal.scale(angle(va, vb) / (2 * n)))
+			}
+		})
+	}
+	c.add(2, moment, p.SphericalArea()/(EarthRadius*EarthRadius))
+}
+
+func (c *sphericalCentroid) addGeometry(g Geometry) {
+	switch t := g.(type) {
+	case Point:
+		c.addPoints([]Point{t})
+	case MultiPoint:
+		c.addPoints(t)
+	case LineString:
+		c.addPath(t, false)
+	case MultiLineString:
+		for _, ls := range t {
+			c.addPath(ls, false)
+		}
+	case Ring:
+		c.addPolygon(Polygon{t})
+	case Polygon:
+		c.addPolygon(t)
+	case MultiPolygon:
+		for _, p := range t {
+			c.addPolygon(p)
+		}
+	case Collection:
+		for _, child := range t {
+			c.addGeometry(child)
+		}
+	}
+}
+
+func sphericalCentroidOf(g Geometry) Point {
+	c := &sphericalCentroid{}
+	c.addGeometry(g)
+	return c.point()
+}
+
+// SphericalCentroid returns the centroid of the points on the sphere, or
+// nil if there are none or they cancel each other out.
+func (m MultiPoint) SphericalCentroid() Point {
+	return sphericalCentroidOf(m)
+}
+
+func (ls LineString) SphericalCentroid() Point {
+	return sphericalCentroidOf(ls)
+}
+
+func (m MultiLineString) SphericalCentroid() Point {
+	return sphericalCentroidOf(m)
+}
+
+func (r Ring) SphericalCentroid() Point {
+	return sphericalCentroidOf(r)
+}
+
+func (p Polygon) SphericalCentroid() Point {
+	return sphericalCentroidOf(p)
+}
+
+func (mp MultiPolygon) SphericalCentroid() Point {
+	return sphericalCentroidOf(mp)
+}
+
+// SphericalCentroid is Centroid on the sphere.
+func (c Collection) SphericalCentroid() Point {
+	return sphericalCentroidOf(c)
+}
+
+// SphericalDistance returns the great circle distance between the two
+// points in metres.
+func (p Point) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(p, point)
+}
+
+func (m MultiPoint) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(m, point)
+}
+
+func (ls LineString) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(ls, point)
+}
+
+func (m MultiLineString) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(m, point)
+}
+
+func (r Ring) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(r, point)
+}
+
+func (p Polygon) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(p, point)
+}
+
+func (mp MultiPolygon) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(mp, point)
+}
+
+// SphericalDistance is Distance on the sphere, in metres.
+func (c Collection) SphericalDistance(point Point) float64 {
+	return sphericalDistanceTo(c, point)
+}
+
+func sphericalDistanceTo(g Geometry, point Point) float64 {
+	return sphericalAngleTo(g, toVector(point), point) * EarthRadius
+}
+
+func sphericalAngleTo(g Geometry, v vector, point Point) float64 {
+	min := math.Inf(1)
+	switch t := g.(type) {
+	case Point:
+		min = angle(toVector(t), v)
+	case MultiPoint:
+		for _, p := range t {
+			min = math.Min(min, angle(toVector(p), v))
+		}
+	case LineString:
+		min = sphericalPathAngle(t, false, v)
+	case MultiLineString:
+		for _, ls := range t {
+			min = math.Min(min, sphericalPathAngle(ls, false, v))
+		}
+	case Ring:
+		min = sphericalAngleTo(Polygon{t}, v, point)
+	case Polygon:
+		if len(t) > 0 && t.locate(point) >= 0 {
+			return 0
+		}
+		for _, r := range t {
+			min = math.Min(min, sphericalPathAngle(r, true, v))
+		}
+	case MultiPolygon:
+		for _, p := range t {
+			min = math.Min(min, sphericalAngleTo(p, v, point))
+		}
+	case Collection:
+		for _, child := range t {
+			min = math.Min(min, sphericalAngleTo(child, v, point))
+		}
+	}
+	return min
+}
+
+func sphericalPathAngle(points []Point, closed bool, v vector) float64 {
+	if len(points) == 1 {
+		return angle(toVector(points[0]), v)
+	}
+	min := math.Inf(1)
+	forEachSegment(points, closed, func(a, b Point) {
+		min = math.Min(min, arcAngle(toVector(a), toVector(b), v))
+	})
+	return min
+}
+
+// arcAngle returns the angle from v to the closest point of the great
+// circle arc from a to b: the cross-track angle if v lies alongside the
+// arc, and the angle to the closer end otherwise.
+func arcAngle(a, b, v vector) float64 {
+	normal := a.cross(b)
+	if n := normal.norm(); n > 0 {
+		normal = normal.scale(1 / n)
+		if a.cross(v).dot(normal) >= 0 && v.cross(b).dot(normal) >= 0 {
+			return math.Asin(math.Min(1, math.Abs(v.dot(normal))))
+		}
+	}
+	return math.Min(angle(a, v), angle(b, v))
+}
@@ -0,0 +1,132 @@
+package geometry
+
+import (
+	"math"
+)
+
+// Transform returns the point f maps p to.
+func (p Point) Transform(f func(Point) Point) Point {
+	return f(p)
+}
+
+// Transform returns the points with f applied to each of them. The points
+// themselves are left untouched.
+func (m MultiPoint) Transform(f func(Point) Point) MultiPoint {
+	return MultiPoint(transformPoints(m, f))
+}
+
+func (ls LineString) Transform(f func(Point) Point) LineString {
+	return LineString(transformPoints(ls, f))
+}
+
+func (m MultiLineString) Transform(f func(Point) Point) MultiLineString {
+	transformed := make(MultiLineString, len(m))
+	for i, ls := range m {
+		transformed[i] = ls.Transform(f)
+	}
+	return transformed
+}
+
+// Transform returns the ring with f applied to each point. A closed ring
+// stays closed as long as f maps equal points to equal points.
+func (r Ring) Transform(f func(Point) Point) Ring {
+	return Ring(transformPoints(r, f))
+}
+
+func (p Polygon) Transform(f func(Point) Point) Polygon {
+	transformed := make(Polygon, len(p))
+	for i, r := range p {
+		transformed[i] = r.Transform(f)
+	}
+	return transformed
+}
+
+func (mp MultiPolygon) Transform(f func(Point) Point) MultiPolygon {
+	transformed := make(MultiPolygon, len(mp))
+	for i, p := range mp {
+		transformed[i] = p.Transform(f)
+	}
+	return transformed
+}
+
+// Transform returns the collection with f applied to every point of every
+// geometry in it, nested collections included.
+func (c Collection) Transform(f func(Point) Point) Collection {
+	transformed := make(Collection, len(c))
+	for i, g := range c {
+		transformed[i] = Transform(g, f)
+	}
+	return transformed
+}
+
+// Transform calls the Transform method of whichever geometry g is. Nil
+// geometries stay nil.
+func Transform(g Geometry, f func(Point) Point) Geometry {
+	switch t := g.(type) {
+	case Point:
+		return t.Transform(f)
+	case MultiPoint:
+		return t.Transform(f)
+	case LineString:
+		return t.Transform(f)
+	case MultiLineString:
+		return t.Transform(f)
+	case Ring:
+		return t.Transform(f)
+	case Polygon:
+		return t.Transform(f)
+	case MultiPolygon:
+		return t.Transform(f)
+	case Collection:
+		return t.Transform(f)
+	}
+	return g
+}
+
+func transformPoints(points []Point, f func(Point) Point) []Point {
+	transformed := make([]Point, len(points))
+	for i, p := range points {
+		transformed[i] = f(p)
+	}
+	return transformed
+}
+
+// mercatorRadius is the WGS84 equatorial radius in metres, which Web
+// Mercator projects the earth as a sphere of.
+const mercatorRadius = 6378137.0
+
+// MaxLatitude is the latitude at which Web Mercator is cut off, making the
+// projected world square.
+const MaxLatitude = 85.0511287798066
+
+// WGS84ToWebMercator projects a longitude and latitude in degrees (EPSG:4326)
+// to Web Mercator metres (EPSG:3857). Latitudes beyond MaxLatitude are
+// clamped to it, and any coordinates beyond the first two are kept as they
+// are.
+func WGS84ToWebMercator(p Point) Point {
+	lon, lat := xy(p)
+	lat = math.Max(-MaxLatitude, math.Min(MaxLatitude, lat))
+	return withXY(p,
+		mercatorRadius*lon*math.Pi/180,
+		mercatorRadius*math.Log(math.Tan(math.Pi/4+lat*math.Pi/360)),
+	)
+}
+
+// WebMercatorToWGS84 is the inverse of WGS84ToWebMercator.
+func WebMercatorToWGS84(p Point) Point {
+	x, y := xy(p)
+	return withXY(p,
+		x/mercatorRadius*180/math.Pi,
+		(2*math.Atan(math.Exp(y/mercatorRadius))-math.Pi/2)*180/math.Pi,
+	)
+}
+
+// withXY returns a copy of p with its first two coordinates set to x and y.
+func withXY(p Point, x, y float64) Point {
+	transformed := make(Point, 2, len(p)+2)
+	transformed[0], transformed[1] = x, y
+	if len(p) > 2 {
+		transformed = append(transformed, p[2:]...)
+	}
+	return transformed
+}
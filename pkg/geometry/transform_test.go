@@ -0,0 +1,86 @@
+package geometry_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+func TestTransform(t *testing.T) {
+	double := func(p Point) Point {
+		return Point{p[0] * 2, p[1] * 2}
+	}
+	polygon := Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}
+	collection := Collection{
+		Point{1, 2},
+		MultiLineString{{{0, 0}, {1, 1}}},
+		Collection{polygon},
+	}
+	expected := Collection{
+		Point{2, 4},
+		MultiLineString{{{0, 0}, {2, 2}}},
+		Collection{Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 0}}}},
+	}
+
+	if got := collection.Transform(double); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, polygon) {
+		t.Errorf("Expected the polygon to be left untouched, got %v", polygon)
+	}
+	if got := Transform(MultiPoint{{1, 1}}, double); !reflect.DeepEqual(MultiPoint{{2, 2}}, got) {
+		t.Errorf("Expected %v, got %v", MultiPoint{{2, 2}}, got)
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	testCases := []struct {
+		WGS84    Point
+		Mercator Point
+	}{
+		{WGS84: Point{0, 0}, Mercator: Point{0, 0}},
+		{WGS84: Point{180, 0}, Mercator: Point{20037508.342789244, 0}},
+		{WGS84: Point{-180, MaxLatitude}, Mercator: Point{-20037508.342789244, 20037508.342789244}},
+		{WGS84: Point{13.4, 52.5, 34}, Mercator: Point{1491681.1766, 6891041.7239, 34}},
+	}
+
+	for i, test := range testCases {
+		projected := WGS84ToWebMercator(test.WGS84)
+		if !projected.EqualWithin(test.Mercator, 1e-4) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Mercator, projected)
+		}
+		if got := WebMercatorToWGS84(projected); !got.EqualWithin(test.WGS84, 1e-9) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.WGS84, got)
+		}
+	}
+
+	// Latitudes beyond the projection's cut off are clamped
+	if got := WGS84ToWebMercator(Point{0, 90}); math.Abs(got[1]-20037508.342789244) > 1e-4 {
+		t.Errorf("Expected %v, got %v", 20037508.342789244, got[1])
+	}
+}
+
+func TestTileAffine(t *testing.T) {
+	testCases := []struct {
+		Z, X, Y, Extent uint32
+		World           Point
+		Grid            Point
+	}{
+		{Z: 0, X: 0, Y: 0, Extent: 4096, World: Point{0, 0}, Grid: Point{2048, 2048}},
+		{Z: 0, X: 0, Y: 0, Extent: 4096, World: Point{-20037508.342789244, 20037508.342789244}, Grid: Point{0, 0}},
+		{Z: 1, X: 1, Y: 1, Extent: 4096, World: Point{20037508.342789244, -20037508.342789244, 12}, Grid: Point{4096, 4096, 12}},
+		{Z: 1, X: 1, Y: 0, Extent: 512, World: Point{10018754.171394622, 10018754.171394622}, Grid: Point{256, 256}},
+	}
+
+	for i, test := range testCases {
+		affine := TileAffine(test.Z, test.X, test.Y, test.Extent)
+		if got := affine.Invert(test.World); !got.EqualWithin(test.Grid, 1e-9) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Grid, got)
+		}
+		if got := affine.Apply(test.Grid); !got.EqualWithin(test.World, 1e-6) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.World, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package geometry
+
+// signedArea is twice the area of the ring in its first two dimensions,
+// positive if it winds counter-clockwise and negative if it winds
+// clockwise. Unclosed rings are treated as if they were closed, and points
+// with fewer than two coordinates are skipped.
+func (r Ring) signedArea() float64 {
+	sum := 0.0
+	for i, p := range r {
+		next := r[(i+1)%len(r)]
+		if len(p) < 2 || len(next) < 2 {
+			continue
+		}
+		sum += p[0]*next[1] - next[0]*p[1]
+	}
+	return sum
+}
+
+// IsClockwise reports whether the ring winds clockwise, going by the sign
+// of its area. Rings without an area, such as ones with fewer than three
+// points, aren't clockwise.
+func (r Ring) IsClockwise() bool {
+	return r.signedArea() < 0
+}
+
+// Reverse returns the ring with its points in reverse order. The ring
+// itself is left untouched.
+func (r Ring) Reverse() Ring {
+	reversed := make(Ring, len(r))
+	for i, p := range r {
+		reversed[len(r)-1-i] = p
+	}
+	return reversed
+}
+
+// Rewind returns the polygon with its rings reversed where needed, so that
+// the exterior ring winds counter-clockwise and holes wind clockwise as RFC
+// 7946 requires, or the other way round if rfc7946 is false. Rings without
+// an area are left as they are.
+func (p Polygon) Rewind(rfc7946 bool) Polygon {
+	rewound := make(Polygon, len(p))
+	for i, r := range p {
+		clockwise := i > 0
+		if !rfc7946 {
+			clockwise = !clockwise
+		}
+		area := r.signedArea()
+		if (clockwise && area > 0) || (!clockwise && area < 0) {
+			r = r.Reverse()
+		}
+		rewound[i] = r
+	}
+	return rewound
+}
+
+// Rewind returns the multi polygon with each of its polygons rewound.
+func (mp MultiPolygon) Rewind(rfc7946 bool) MultiPolygon {
+	rewound := make(MultiPolygon, len(mp))
+	for i, p := range mp {
+		rewound[i] = p.Rewind(rfc7946)
+	}
+	return rewound
+}
@@ -0,0 +1,86 @@
+package geometry_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+var (
+	counterClockwise = Ring{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	clockwise        = Ring{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}}
+)
+
+func TestRingIsClockwise(t *testing.T) {
+	testCases := []struct {
+		Ring     Ring
+		Expected bool
+	}{
+		{Ring: counterClockwise, Expected: false},
+		{Ring: clockwise, Expected: true},
+		// Unclosed rings wind the same way as their closed counterparts
+		{Ring: Ring{{1, 1}, {1, 2}, {2, 2}, {2, 1}}, Expected: true},
+		{Ring: Ring{{0, 0}, {1, 1}, {0, 0}}, Expected: false},
+		{Ring: Ring{}, Expected: false},
+	}
+
+	for i, test := range testCases {
+		if got := test.Ring.IsClockwise(); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t, got %t", i, test.Expected, got)
+		}
+	}
+}
+
+func TestRingReverse(t *testing.T) {
+	ring := Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}
+	expected := Ring{{0, 0}, {1, 1}, {1, 0}, {0, 0}}
+	if got := ring.Reverse(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if ring.IsClockwise() == ring.Reverse().IsClockwise() {
+		t.Errorf("Expected reversing to change the winding order")
+	}
+}
+
+func TestPolygonRewind(t *testing.T) {
+	testCases := []struct {
+		Polygon  Polygon
+		RFC7946  bool
+		Expected Polygon
+	}{
+		{
+			Polygon:  Polygon{counterClockwise, clockwise},
+			RFC7946:  true,
+			Expected: Polygon{counterClockwise, clockwise},
+		},
+		{
+			Polygon:  Polygon{counterClockwise.Reverse(), clockwise.Reverse()},
+			RFC7946:  true,
+			Expected: Polygon{counterClockwise, clockwise},
+		},
+		{
+			Polygon:  Polygon{counterClockwise, clockwise},
+			RFC7946:  false,
+			Expected: Polygon{counterClockwise.Reverse(), clockwise.Reverse()},
+		},
+	}
+
+	for i, test := range testCases {
+		if got := test.Polygon.Rewind(test.RFC7946); !reflect.DeepEqual(test.Expected, got) {
+			t.Errorf("Case [%d]: Expected %v, got %v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestMultiPolygonRewind(t *testing.T) {
+	mp := MultiPolygon{{clockwise}, {counterClockwise, counterClockwise}}
+	expected := MultiPolygon{{clockwise.Reverse()}, {counterClockwise, counterClockwise.Reverse()}}
+	if got := mp.Rewind(true); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	// The original is left untouched
+	if !mp[0][0].IsClockwise() {
+		t.Errorf("Expected the original polygon to keep its winding order")
+	}
+}
@@ -8,17 +8,100 @@ var (
 	MaxPrecision = uint(math.Pow10(9))
 )
 
+// GetPrecision returns the power of 10 needed to represent point without
+// losing digits, up to MaxPrecision.
 func GetPrecision(point float64) uint {
-	var e uint = 1
-	for {
-		base := math.Round(float64(point * float64(e)))
-		if (base/float64(e)) != point && e < MaxPrecision {
-			e = e * 10
-		} else {
-			break
+	return GetPrecisionUpTo(point, MaxPrecision)
+}
+
+// GetPrecisionUpTo is GetPrecision with a lower limit than MaxPrecision,
+// which saves looking for digits that wouldn't be used anyway.
+//
+// Rather than trying each power of 10 in turn, the point is scaled once, by
+// the largest power that keeps it below 2^52. Scaled that far, whole numbers
+// are exact and neighbouring ones decode to different points, so if any
+// precision gives the point back this one does, with the decimal digits the
+// point needs followed by zeros. Counting those zeros gives the precision.
+// Only points too large to scale that far try the powers beyond it.
+func GetPrecisionUpTo(point float64, max uint) uint {
+	if point != point {
+		return max
+	}
+	if point == math.Trunc(point) {
+		return 1
+	}
+	// Digits below max, the most worth looking for
+	limit := len(powers) - 1
+	for limit > 0 && powers[limit] >= float64(max) {
+		limit--
+	}
+
+	// Scaled by 10^digits, the point stays below 2^52 and isn't whole, so
+	// fewer than digits zeros follow its own decimals
+	point = math.Abs(point)
+	exp := int(math.Float64bits(point)>>52) - 1022
+	digits := int(float64(52-exp) * log10Of2)
+	if digits > limit {
+		digits = limit
+	}
+	if scaled := math.Round(point * powers[digits]); scaled/powers[digits] == point {
+		return uint(powers[digits-trailingZeros(uint64(scaled))])
+	}
+	for digits++; digits <= limit; digits++ {
+		if math.Round(point*powers[digits])/powers[digits] == point {
+			return uint(powers[digits])
+		}
+	}
+	return max
+}
+
+// trailingZeros counts the zeros n ends with in decimal, up to 15. The
+// divisions are by constants, which compile to multiplications.
+func trailingZeros(n uint64) int {
+	zeros := 0
+	if n%1e8 == 0 {
+		n /= 1e8
+		zeros += 8
+	}
+	if n%1e4 == 0 {
+		n /= 1e4
+		zeros += 4
+	}
+	if n%1e2 == 0 {
+		n /= 1e2
+		zeros += 2
+	}
+	if n%10 == 0 {
+		zeros++
+	}
+	return zeros
+}
+
+// powers holds every power of 10 that fits in a uint on all platforms, so
+// precisions beyond 1e9 can't be detected.
+var powers = [...]float64{1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9}
+
+const log10Of2 = 0.30102999566398119521
+
+// MaxScaled bounds scaled coordinates, so that the delta between any two of
+// them still fits in an int64.
+const MaxScaled = 1 << 62
+
+// CanScale reports whether point can be scaled by precision and still be
+// delta encoded. NaN and infinite points can't be scaled at all.
+func CanScale(point float64, precision uint) bool {
+	return math.Abs(point*float64(precision)) < MaxScaled
+}
+
+// MaxPrecisionFor returns the highest power of 10, up to MaxPrecision, that
+// point can be scaled by, or 0 if it can't be scaled at all.
+func MaxPrecisionFor(point float64) uint {
+	for i := len(powers) - 1; i >= 0; i-- {
+		if e := uint(powers[i]); e <= MaxPrecision && CanScale(point, e) {
+			return e
 		}
 	}
-	return e
+	return 0
 }
 
 func IntWithPrecision(point float64, precision uint) int64 {
@@ -0,0 +1,173 @@
+package math_test
+
+import (
+	gomath "math"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf/pkg/math"
+)
+
+// oldGetPrecision is the original loop based implementation, kept to check
+// the new one against and to compare their speed.
+func oldGetPrecision(point float64) uint {
+	var e uint = 1
+	for {
+		base := gomath.Round(float64(point * float64(e)))
+		if (base/float64(e)) != point && e < MaxPrecision {
+			e = e * 10
+		} else {
+			break
+		}
+	}
+	return e
+}
+
+var precisionValues = []float64{
+	0, 1, -1, 10, 1e20, 124.123, -124.123, 234.456, 0.5, -0.5, 0.30000000000000004,
+	174.7633315, -36.8484597, 1.23e-5, -1.23e-5, 1e-12, 123456789.123, 5e-324,
+	gomath.MaxFloat64, gomath.Pi, 12.000001, 4503599627370497,
+	// Large enough that scaling them ends on a half
+	3.651749974832864e+10, 4.476775004041166e+09, 4503599627370495.5,
+	20037508.342789244, 2.749678632221894e+08,
+}
+
+func TestGetPrecision(t *testing.T) {
+	testCases := []struct {
+		Value    float64
+		Expected uint
+	}{
+		{0, 1},
+		{42, 1},
+		{-42, 1},
+		{1e20, 1},
+		{124.123, 1000},
+		{-124.123, 1000},
+		{0.5, 10},
+		{-36.8484597, 1e7},
+		{1.23e-5, 1e7},
+		{0.30000000000000004, MaxPrecision},
+		{1e-12, MaxPrecision},
+		{gomath.Inf(1), 1},
+		{gomath.NaN(), MaxPrecision},
+	}
+
+	for i, test := range testCases {
+		if got := GetPrecision(test.Value); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %d for %v, got %d", i, test.Expected, test.Value, got)
+		}
+	}
+}
+
+func TestGetPrecisionUpTo(t *testing.T) {
+	testCases := []struct {
+		Value    float64
+		Max      uint
+		Expected uint
+	}{
+		{124.123, 1e3, 1e3},
+		{124.123, 1e4, 1e3},
+		{124.123, 100, 100},
+		{-36.8484597, 1e5, 1e5},
+		{1e-12, 10, 10},
+		{42, 1, 1},
+		{0.5, 1, 1},
+	}
+
+	for i, test := range testCases {
+		if got := GetPrecisionUpTo(test.Value, test.Max); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %d for %v up to %d, got %d", i, test.Expected, test.Value, test.Max, got)
+		}
+	}
+}
+
+func TestGetPrecisionMatchesLoop(t *testing.T) {
+	for i, val := range precisionValues {
+		if expected, got := oldGetPrecision(val), GetPrecision(val); expected != got {
+			t.Errorf("Case [%d]: Expected %d for %v, got %d", i, expected, val, got)
+		}
+	}
+}
+
+func TestGetPrecisionAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, val := range precisionValues {
+			GetPrecision(val)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+// benchmarkValues holds typical inputs: integer grids, surveyed coordinates
+// and the noise left behind by reprojection.
+var benchmarkValues = map[string][]float64{
+	"Integers":    {0, 1, 4096, -512, 180, -90, 1e6, 37},
+	"Coordinates": {174.7633315, -36.8484597, 174.763, -36.848, 2.3522219, 48.856614, -0.1276, 51.5072},
+	"Noisy":       {174.76333150000001, -36.848459700000003, 0.30000000000000004, gomath.Pi, gomath.E, 1.0 / 3, 2.0 / 3, gomath.Sqrt2},
+}
+
+func benchmarkPrecision(b *testing.B, fn func(float64) uint) {
+	for _, name := range []string{"Integers", "Coordinates", "Noisy"} {
+		values := benchmarkValues[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fn(values[i%len(values)])
+			}
+		})
+	}
+}
+
+func BenchmarkGetPrecision(b *testing.B) {
+	benchmarkPrecision(b, GetPrecision)
+}
+
+func BenchmarkGetPrecisionLoop(b *testing.B) {
+	benchmarkPrecision(b, oldGetPrecision)
+}
+
+func TestCanScale(t *testing.T) {
+	testCases := []struct {
+		Value     float64
+		Precision uint
+		Expected  bool
+	}{
+		{0, MaxPrecision, true},
+		{-20037508.342789244, MaxPrecision, true},
+		{4.6e9, 1e9, true},
+		{4.7e9, 1e9, false},
+		{-4.7e9, 1e9, false},
+		{float64(MaxScaled) - 1024, 1, true},
+		{MaxScaled, 1, false},
+		{gomath.NaN(), 1, false},
+		{gomath.Inf(1), 1, false},
+	}
+
+	for i, test := range testCases {
+		if got := CanScale(test.Value, test.Precision); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %t for %v at %d, got %t", i, test.Expected, test.Value, test.Precision, got)
+		}
+	}
+}
+
+func TestMaxPrecisionFor(t *testing.T) {
+	testCases := []struct {
+		Value    float64
+		Expected uint
+	}{
+		{0, MaxPrecision},
+		{174.7633315, MaxPrecision},
+		{4.7e9, 1e8},
+		{-4.7e12, 1e5},
+		{float64(MaxScaled) - 1024, 1},
+		{MaxScaled, 0},
+		{gomath.NaN(), 0},
+	}
+
+	for i, test := range testCases {
+		if got := MaxPrecisionFor(test.Value); got != test.Expected {
+			t.Errorf("Case [%d]: Expected %d for %v, got %d", i, test.Expected, test.Value, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package validate
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+// distinct drops points that repeat the position of the point before them,
+// returning the remaining points along with their index in ring.
+func distinct(ring geometry.Ring) ([]geometry.Point, []int) {
+	points := make([]geometry.Point, 0, len(ring))
+	starts := make([]int, 0, len(ring))
+	for i, point := range ring {
+		if n := len(points); n > 0 && samePosition(points[n-1], point) {
+			continue
+		}
+		points = append(points, point)
+		starts = append(starts, i)
+	}
+	return points, starts
+}
+
+func samePosition(a, b geometry.Point) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+// edge is a segment of a ring, along with its box in the first two
+// dimensions.
+type edge struct {
+	ring, index            int
+	a, b                   geometry.Point
+	minX, minY, maxX, maxY float64
+}
+
+// appendEdges adds the edges between consecutive points to edges.
+func appendEdges(edges []edge, ring int, points []geometry.Point) []edge {
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		edges = append(edges, edge{
+			ring: ring, index: i, a: a, b: b,
+			minX: math.Min(a[0], b[0]), minY: math.Min(a[1], b[1]),
+			maxX: math.Max(a[0], b[0]), maxY: math.Max(a[1], b[1]),
+		})
+	}
+	return edges
+}
+
+// overlappingEdges calls f for every pair of edges whose boxes overlap.
+// Edges are swept from left to right, so that each is only compared with
+// those that overlap it along x rather than with all of them.
+func overlappingEdges(edges []edge, f func(e, o edge)) {
+	order := make([]int, len(edges))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return edges[order[i]].minX < edges[order[j]].minX
+	})
+
+	var active []int
+	for _, i := range order {
+		e := edges[i]
+		kept := active[:0]
+		for _, j := range active {
+			if edges[j].maxX >= e.minX {
+				kept = append(kept, j)
+			}
+		}
+		active = kept
+		for _, j := range active {
+			if o := edges[j]; o.minY <= e.maxY && e.minY <= o.maxY {
+				f(o, e)
+			}
+		}
+		active = append(active, i)
+	}
+}
+
+// selfIntersection finds the first two edges of a closed ring without
+// repeated points that touch anywhere other than the point they share, if
+// they follow each other.
+func selfIntersection(points []geometry.Point) (int, int, bool) {
+	edges := len(points) - 1
+	first, second, found := 0, 0, false
+	overlappingEdges(appendEdges(nil, 0, points), func(e, o edge) {
+		i, j := e.index, o.index
+		if i > j {
+			i, j = j, i
+		}
+		if found && (i > first || i == first && j > second) {
+			return
+		}
+		var touch bool
+		switch {
+		case j == i+1:
+			touch = overlaps(points[i], points[i+1], points[j+1])
+		case i == 0 && j == edges-1:
+			touch = overlaps(points[1], points[0], points[j])
+		default:
+			touch = geometry.SegmentsIntersect(points[i], points[i+1], points[j], points[j+1])
+		}
+		if touch {
+			first, second, found = i, j, true
+		}
+	})
+	return first, second, found
+}
+
+// overlaps reports whether the edges a-shared and shared-b run over each
+// other, rather than only meeting at shared.
+func overlaps(a, shared, b geometry.Point) bool {
+	return geometry.OnSegment(b, a, shared) || geometry.OnSegment(a, shared, b)
+}
+
+// crosses reports whether the segments a-b and c-d cross each other at a
+// single point inside both of them.
+func crosses(a, b, c, d geometry.Point) bool {
+	return geometry.Orientation(a, b, c)*geometry.Orientation(a, b, d) < 0 &&
+		geometry.Orientation(c, d, a)*geometry.Orientation(c, d, b) < 0
+}
@@ -0,0 +1,222 @@
+// Package validate checks geometries for the problems that make them
+// invalid GeoJSON, or that most tools can't handle: coordinates that aren't
+// finite numbers, lines and rings with too few points, unclosed or
+// self-intersecting rings, and holes outside their polygon.
+//
+// The shape of a geometry is only checked in its first two dimensions,
+// while every coordinate has to be finite.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+var (
+	ErrInvalidCoordinate  = errors.New("coordinate is not a finite number")
+	ErrMissingCoordinates = errors.New("point has fewer than 2 coordinates")
+	ErrTooFewPoints       = errors.New("too few points")
+	ErrUnclosedRing       = errors.New("ring is not closed")
+	ErrSelfIntersection   = errors.New("ring intersects itself")
+	ErrHoleOutside        = errors.New("hole is not inside the exterior ring")
+)
+
+const (
+	// MinLinePoints is the fewest points a line string can have.
+	MinLinePoints = 2
+	// MinRingPoints is the fewest points a ring can have, including the
+	// closing point.
+	MinRingPoints = 4
+)
+
+// Error reports where a geometry is invalid. The path follows the layout of
+// the geometry's GeoJSON, e.g. "coordinates[1][0]" for the exterior ring of
+// the second polygon of a multi polygon, or "geometries[2].coordinates[4]"
+// for the fifth point of a line in a collection.
+type Error struct {
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Geometry returns the first problem found in g, as an *Error, or nil if
+// it's valid. Nil geometries and empty multi geometries are valid.
+func Geometry(g geometry.Geometry) error {
+	return checkGeometry(g, "")
+}
+
+func checkGeometry(g geometry.Geometry, prefix string) error {
+	path := prefix + "coordinates"
+	switch t := g.(type) {
+	case geometry.Point:
+		return checkPoint(t, path)
+	case geometry.MultiPoint:
+		return checkPoints(t, path, 0)
+	case geometry.LineString:
+		return checkPoints(t, path, MinLinePoints)
+	case geometry.MultiLineString:
+		for i, line := range t {
+			if err := checkPoints(line, index(path, i), MinLinePoints); err != nil {
+				return err
+			}
+		}
+	case geometry.Ring:
+		return checkRing(t, path)
+	case geometry.Polygon:
+		return checkPolygon(t, path)
+	case geometry.MultiPolygon:
+		for i, polygon := range t {
+			if err := checkPolygon(polygon, index(path, i)); err != nil {
+				return err
+			}
+		}
+	case geometry.Collection:
+		for i, child := range t {
+			if err := checkGeometry(child, fmt.Sprintf("%sgeometries[%d].", prefix, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func checkPoint(point geometry.Point, path string) error {
+	if len(point) < 2 {
+		return &Error{Path: path, Err: ErrMissingCoordinates}
+	}
+	for i, coord := range point {
+		if math.IsNaN(coord) || math.IsInf(coord, 0) {
+			return &Error{Path: index(path, i), Err: ErrInvalidCoordinate}
+		}
+	}
+	return nil
+}
+
+func checkPoints(points []geometry.Point, path string, min int) error {
+	if len(points) < min {
+		return &Error{Path: path, Err: fmt.Errorf("%w: %d, need at least %d", ErrTooFewPoints, len(points), min)}
+	}
+	// Paths are only worked out for the point at fault
+	for i, point := range points {
+		if !validPoint(point) {
+			return checkPoint(point, index(path, i))
+		}
+	}
+	return nil
+}
+
+func validPoint(point geometry.Point) bool {
+	if len(point) < 2 {
+		return false
+	}
+	for _, coord := range point {
+		if math.IsNaN(coord) || math.IsInf(coord, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkRing(ring geometry.Ring, path string) error {
+	if err := checkPoints(ring, path, MinRingPoints); err != nil {
+		return err
+	}
+	if !ring.IsClosed() {
+		return &Error{Path: path, Err: ErrUnclosedRing}
+	}
+
+	// Repeated points are harmless, but would make their neighbouring
+	// edges look like they touch
+	points, starts := distinct(ring)
+	if len(points) < MinRingPoints {
+		return &Error{Path: path, Err: fmt.Errorf("%w: %d distinct, need at least %d", ErrTooFewPoints, len(points)-1, MinRingPoints-1)}
+	}
+	if i, j, ok := selfIntersection(points); ok {
+		return &Error{Path: path, Err: fmt.Errorf("%w: edges %d and %d", ErrSelfIntersection, starts[i], starts[j])}
+	}
+	return nil
+}
+
+func checkPolygon(polygon geometry.Polygon, path string) error {
+	for i, ring := range polygon {
+		if err := checkRing(ring, index(path, i)); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < len(polygon); i++ {
+		if err := checkHole(polygon[0], polygon[i]); err != nil {
+			return &Error{Path: index(path, i), Err: err}
+		}
+	}
+	return nil
+}
+
+// Hole returns an error wrapping ErrHoleOutside if hole isn't a valid hole
+// of a polygon with the exterior ring, as Geometry checks it, or nil if it
+// is. Neither ring is checked by itself.
+func Hole(exterior, hole geometry.Ring) error {
+	return checkHole(exterior, hole)
+}
+
+// checkHole makes sure no point of hole lies outside of exterior, and that
+// none of their edges cross. Holes may touch the exterior ring, so an edge
+// between two points on it is checked by its middle. A hole that doesn't
+// meet the exterior ring at all lies entirely inside or outside of it, so
+// then its first point is enough to tell which.
+func checkHole(exterior, hole geometry.Ring) error {
+	meets := false
+	crossing, crossed := -1, -1
+	edges := appendEdges(appendEdges(nil, 0, exterior), 1, hole)
+	overlappingEdges(edges, func(e, o edge) {
+		if e.ring == o.ring {
+			return
+		}
+		if e.ring == 1 {
+			e, o = o, e
+		}
+		if !geometry.SegmentsIntersect(o.a, o.b, e.a, e.b) {
+			return
+		}
+		meets = true
+		if crosses(o.a, o.b, e.a, e.b) && (crossing < 0 || o.index < crossing || o.index == crossing && e.index < crossed) {
+			crossing, crossed = o.index, e.index
+		}
+	})
+
+	points := hole
+	if !meets {
+		points = hole[:1]
+	}
+	for i, point := range points {
+		if exterior.Locate(point) < 0 {
+			return fmt.Errorf("%w: point %d", ErrHoleOutside, i)
+		}
+	}
+	if !meets {
+		return nil
+	}
+	for i := 0; i+1 < len(hole); i++ {
+		middle := geometry.Point{(hole[i][0] + hole[i+1][0]) / 2, (hole[i][1] + hole[i+1][1]) / 2}
+		if exterior.Locate(middle) < 0 {
+			return fmt.Errorf("%w: edge %d", ErrHoleOutside, i)
+		}
+		if i == crossing {
+			return fmt.Errorf("%w: edge %d crosses edge %d of the exterior ring", ErrHoleOutside, i, crossed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package validate_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+	. "github.com/cairnapp/go-geobuf/pkg/validate"
+)
+
+func square(x, y, size float64) geometry.Ring {
+	return geometry.Ring{
+		{x, y},
+		{x + size, y},
+		{x + size, y + size},
+		{x, y + size},
+		{x, y},
+	}
+}
+
+func TestGeometryValid(t *testing.T) {
+	testCases := []geometry.Geometry{
+		nil,
+		geometry.Point{1, 2},
+		geometry.Point{1, 2, 3},
+		geometry.MultiPoint{},
+		geometry.LineString{{0, 0}, {1, 1}},
+		geometry.LineString{{0, 0}, {1, 1}, {0, 0}},
+		square(0, 0, 1),
+		// Repeated points
+		geometry.Ring{{0, 0}, {1, 0}, {1, 0}, {1, 1}, {0, 0}, {0, 0}},
+		geometry.Polygon{square(0, 0, 10), square(1, 1, 2), square(5, 5, 2)},
+		// Holes may touch the exterior ring
+		geometry.Polygon{square(0, 0, 10), square(0, 0, 2)},
+		// A sliver whose last vertex is just off its first edge
+		geometry.Ring{{0.5, 0.5000000000000001}, {24, 24}, {30, 0}, {12, 12}, {0.5, 0.5000000000000001}},
+		geometry.MultiPolygon{{square(0, 0, 1)}, {square(5, 5, 1)}},
+		geometry.Collection{geometry.Point{1, 2}, geometry.Collection{square(0, 0, 1)}},
+	}
+
+	for i, test := range testCases {
+		if err := Geometry(test); err != nil {
+			t.Errorf("Case [%d]: Got unexpected error %s!", i, err)
+		}
+	}
+}
+
+func TestGeometryInvalid(t *testing.T) {
+	testCases := []struct {
+		Geometry geometry.Geometry
+		Err      error
+		Message  string
+	}{
+		{
+			Geometry: geometry.Point{1, math.NaN()},
+			Err:      ErrInvalidCoordinate,
+			Message:  "coordinates[1]: coordinate is not a finite number",
+		},
+		{
+			Geometry: geometry.MultiPoint{{1, 2}, {3}},
+			Err:      ErrMissingCoordinates,
+			Message:  "coordinates[1]: point has fewer than 2 coordinates",
+		},
+		{
+			Geometry: geometry.MultiLineString{{{0, 0}, {1, 1}}, {{0, 0}}},
+			Err:      ErrTooFewPoints,
+			Message:  "coordinates[1]: too few points: 1, need at least 2",
+		},
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {0, 0}}},
+			Err:      ErrTooFewPoints,
+			Message:  "coordinates[0]: too few points: 3, need at least 4",
+		},
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {1, 0}, {0, 0}}},
+			Err:      ErrTooFewPoints,
+			Message:  "coordinates[0]: too few points: 2 distinct, need at least 3",
+		},
+		{
+			Geometry: geometry.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}},
+			Err:      ErrUnclosedRing,
+			Message:  "coordinates[0]: ring is not closed",
+		},
+		{
+			// A bow tie
+			Geometry: geometry.Polygon{{{0, 0}, {1, 1}, {1, 0}, {0, 1}, {0, 0}}},
+			Err:      ErrSelfIntersection,
+			Message:  "coordinates[0]: ring intersects itself: edges 0 and 2",
+		},
+		{
+			// A spike doubling back over its edge
+			Geometry: geometry.Polygon{{{0, 0}, {2, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			Err:      ErrSelfIntersection,
+			Message:  "coordinates[0]: ring intersects itself: edges 0 and 1",
+		},
+		{
+			// Touching itself at a vertex
+			Geometry: geometry.Polygon{{{0, 0}, {2, 0}, {1, 1}, {2, 2}, {0, 2}, {1, 1}, {0, 0}}},
+			Err:      ErrSelfIntersection,
+			Message:  "coordinates[0]: ring intersects itself: edges 1 and 4",
+		},
+		{
+			Geometry: geometry.Polygon{square(0, 0, 10), square(20, 20, 1)},
+			Err:      ErrHoleOutside,
+			Message:  "coordinates[1]: hole is not inside the exterior ring: point 0",
+		},
+		{
+			// Every point of the hole is on the exterior ring, but the hole
+			// fills its notch
+			Geometry: geometry.Polygon{
+				{{0, 0}, {4, 0}, {4, 4}, {2, 1}, {0, 4}, {0, 0}},
+				{{0, 4}, {2, 1}, {2, 1}, {4, 4}, {0, 4}},
+			},
+			Err:     ErrHoleOutside,
+			Message: "coordinates[1]: hole is not inside the exterior ring: edge 3",
+		},
+		{
+			Geometry: geometry.MultiPolygon{{square(0, 0, 1)}, {square(0, 0, 1), geometry.Ring{{0, 0}, {2, 2}, {0, 2}, {0, 0}}}},
+			Err:      ErrHoleOutside,
+			Message:  "coordinates[1][1]: hole is not inside the exterior ring: point 1",
+		},
+		{
+			Geometry: geometry.Collection{geometry.Point{1, 2}, geometry.Collection{geometry.LineString{{0, 0}, {math.Inf(1), 0}}}},
+			Err:      ErrInvalidCoordinate,
+			Message:  "geometries[1].geometries[0].coordinates[1][0]: coordinate is not a finite number",
+		},
+	}
+
+	for i, test := range testCases {
+		err := Geometry(test.Geometry)
+		var validationErr *Error
+		if !errors.As(err, &validationErr) || !errors.Is(err, test.Err) {
+			t.Errorf("Case [%d]: Expected %s, got %v", i, test.Err, err)
+			continue
+		}
+		if test.Message != "" && err.Error() != test.Message {
+			t.Errorf("Case [%d]: Expected %q, got %q", i, test.Message, err)
+		}
+	}
+}
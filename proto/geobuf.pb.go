@@ -5,9 +5,11 @@
 Package proto is a generated protocol buffer package.
 
 It is generated from these files:
+
 	geobuf.proto
 
 It has these top-level messages:
+
 	Data
 */
 package proto
@@ -64,9 +66,11 @@ func (x Data_Geometry_Type) String() string {
 func (Data_Geometry_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 1, 0} }
 
 type Data struct {
-	Keys       []string `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
-	Dimensions uint32   `protobuf:"varint,2,opt,name=dimensions" json:"dimensions,omitempty"`
-	Precision  uint32   `protobuf:"varint,3,opt,name=precision" json:"precision,omitempty"`
+	Keys                []string  `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+	Dimensions          uint32    `protobuf:"varint,2,opt,name=dimensions" json:"dimensions,omitempty"`
+	Precision           uint32    `protobuf:"varint,3,opt,name=precision" json:"precision,omitempty"`
+	DimensionPrecisions []uint32  `protobuf:"varint,7,rep,packed,name=dimension_precisions,json=dimensionPrecisions" json:"dimension_precisions,omitempty"`
+	Transform           []float64 `protobuf:"fixed64,8,rep,packed,name=transform" json:"transform,omitempty"`
 	// Types that are valid to be assigned to DataType:
 	//	*Data_FeatureCollection_
 	//	*Data_Feature_
@@ -125,6 +129,20 @@ func (m *Data) GetPrecision() uint32 {
 	return 0
 }
 
+func (m *Data) GetDimensionPrecisions() []uint32 {
+	if m != nil {
+		return m.DimensionPrecisions
+	}
+	return nil
+}
+
+func (m *Data) GetTransform() []float64 {
+	if m != nil {
+		return m.Transform
+	}
+	return nil
+}
+
 func (m *Data) GetFeatureCollection() *Data_FeatureCollection {
 	if x, ok := m.GetDataType().(*Data_FeatureCollection_); ok {
 		return x.FeatureCollection
@@ -704,45 +722,47 @@ func init() {
 func init() { proto1.RegisterFile("geobuf.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 632 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x94, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0x86, 0xed, 0xd8, 0x71, 0xe2, 0x49, 0xda, 0xba, 0x03, 0x14, 0x2b, 0x42, 0xc5, 0x2a, 0x1c,
-	0x2c, 0x90, 0x22, 0x68, 0xc5, 0x0b, 0xb4, 0xa4, 0x89, 0xa5, 0x34, 0xae, 0x96, 0x80, 0x54, 0x2e,
-	0x51, 0x12, 0x6f, 0x83, 0x21, 0xf5, 0x5a, 0xf6, 0x06, 0x29, 0x07, 0x5e, 0x84, 0x03, 0x2f, 0x88,
-	0x78, 0x02, 0x2e, 0x68, 0xd7, 0x1b, 0xd7, 0x45, 0x2d, 0x52, 0x0f, 0x9c, 0x92, 0xf9, 0xe7, 0xdb,
-	0xdf, 0x3b, 0xb3, 0xb3, 0x0b, 0xed, 0x05, 0x65, 0xb3, 0xd5, 0x65, 0x37, 0xcd, 0x18, 0x67, 0x68,
-	0x15, 0xd1, 0xc1, 0x77, 0x00, 0xf3, 0xed, 0x94, 0x4f, 0x11, 0xc1, 0xfc, 0x42, 0xd7, 0xb9, 0xab,
-	0x7b, 0x86, 0x6f, 0x13, 0xf9, 0x1f, 0xf7, 0x01, 0xa2, 0xf8, 0x8a, 0x26, 0x79, 0xcc, 0x92, 0xdc,
-	0xad, 0x79, 0xba, 0xbf, 0x45, 0x2a, 0x0a, 0x3e, 0x01, 0x3b, 0xcd, 0xe8, 0x3c, 0x16, 0x91, 0x6b,
-	0xc8, 0xf4, 0xb5, 0x80, 0x21, 0xe0, 0x25, 0x9d, 0xf2, 0x55, 0x46, 0x27, 0x73, 0xb6, 0x5c, 0xd2,
-	0x39, 0x17, 0x98, 0xe9, 0xe9, 0x7e, 0xeb, 0x70, 0xbf, 0xab, 0x76, 0x23, 0xbe, 0xdd, 0x3d, 0x2d,
-	0xb0, 0x93, 0x92, 0x1a, 0x68, 0x64, 0xf7, 0xf2, 0x6f, 0x11, 0x5f, 0x41, 0x43, 0x89, 0x6e, 0x5d,
-	0xba, 0x3c, 0xbc, 0xcd, 0x65, 0xa0, 0x91, 0x0d, 0x86, 0x47, 0xd0, 0x5c, 0x50, 0x76, 0x45, 0x79,
-	0xb6, 0x76, 0x2d, 0xb9, 0xe4, 0xd1, 0x8d, 0x25, 0x7d, 0x95, 0x1c, 0x68, 0xa4, 0x04, 0x3b, 0x3f,
-	0x75, 0x68, 0x28, 0x2f, 0x7c, 0x5d, 0x31, 0xd0, 0xff, 0x61, 0x70, 0xbd, 0x1c, 0x1d, 0xa8, 0xc5,
-	0x91, 0xdb, 0xf2, 0x74, 0xdf, 0x1e, 0x68, 0xa4, 0x16, 0x47, 0xf8, 0x18, 0xac, 0x38, 0xe1, 0x93,
-	0x38, 0x72, 0xdb, 0x9e, 0xee, 0xe3, 0x40, 0x23, 0xf5, 0x38, 0xe1, 0x41, 0x84, 0x2f, 0xc0, 0xfa,
-	0x3a, 0x5d, 0xae, 0x68, 0xee, 0x6e, 0x79, 0x86, 0xdf, 0x3a, 0xc4, 0x1b, 0xde, 0x1f, 0x44, 0x8a,
-	0x28, 0x42, 0x9c, 0x45, 0x9a, 0xb1, 0x94, 0x66, 0x3c, 0xa6, 0xb9, 0xbb, 0xed, 0x19, 0xe2, 0x2c,
-	0xae, 0x15, 0x7c, 0x09, 0xbb, 0xf3, 0x55, 0xce, 0xd9, 0xd5, 0xa4, 0x82, 0xed, 0x48, 0xcc, 0x29,
-	0x12, 0xe7, 0xa5, 0x7e, 0x6c, 0x43, 0x23, 0x8e, 0x26, 0x7c, 0x9d, 0xd2, 0xce, 0xef, 0x1a, 0x34,
-	0x37, 0x55, 0x60, 0x17, 0x4c, 0x21, 0xca, 0x52, 0xb7, 0x0f, 0x3b, 0xb7, 0x96, 0xda, 0x1d, 0xaf,
-	0x53, 0x4a, 0x24, 0x87, 0x2e, 0x34, 0x96, 0x34, 0x59, 0xf0, 0x4f, 0x62, 0x3a, 0xc4, 0xa7, 0x36,
-	0x21, 0xee, 0x81, 0x35, 0x67, 0x2c, 0x8b, 0x72, 0xd7, 0xf0, 0x0c, 0x1f, 0x89, 0x8a, 0xf0, 0x0d,
-	0x80, 0xea, 0x94, 0xd8, 0x9f, 0x29, 0xcb, 0xbe, 0xa3, 0xa5, 0x15, 0xf0, 0x5e, 0x9d, 0xba, 0x4f,
-	0x27, 0x0e, 0xbe, 0x81, 0x29, 0xea, 0x41, 0x1b, 0xea, 0xe7, 0x61, 0x30, 0x1a, 0x3b, 0x1a, 0x6e,
-	0x03, 0x9c, 0xbd, 0x1f, 0x8e, 0x83, 0x22, 0xd6, 0x45, 0x3c, 0x0c, 0x46, 0xbd, 0x77, 0x63, 0x12,
-	0x8c, 0xfa, 0x4e, 0x0d, 0x1f, 0xc0, 0x8e, 0xcc, 0x57, 0x44, 0x03, 0x5b, 0xd0, 0x38, 0x0f, 0x87,
-	0x17, 0xfd, 0x70, 0xe4, 0x98, 0xe8, 0x40, 0x5b, 0x39, 0x14, 0x4a, 0x1d, 0xf7, 0x00, 0xfb, 0xbd,
-	0xf0, 0xac, 0x37, 0x26, 0x17, 0x27, 0xe1, 0x70, 0xd8, 0x3b, 0x19, 0x07, 0xe1, 0xc8, 0xb1, 0x3a,
-	0x3f, 0x74, 0xd8, 0x3d, 0xbd, 0x65, 0xd0, 0x9b, 0x6a, 0x82, 0x8b, 0xfb, 0x78, 0xc7, 0xa4, 0x93,
-	0x92, 0xfa, 0x6f, 0xfd, 0xe9, 0xfc, 0xd2, 0xa1, 0x2e, 0x97, 0xe3, 0x33, 0x68, 0xe7, 0x3c, 0x8b,
-	0x93, 0xc5, 0x44, 0xfa, 0xc8, 0x19, 0x11, 0x13, 0xde, 0x2a, 0xd4, 0x12, 0x8a, 0xd8, 0x6a, 0xb6,
-	0xa4, 0x0a, 0x12, 0x6f, 0x86, 0x2e, 0xa0, 0x42, 0x2d, 0xa0, 0xe7, 0xb0, 0x95, 0xb2, 0x7c, 0x22,
-	0xee, 0x44, 0x41, 0x89, 0xa7, 0xc3, 0x14, 0x54, 0xca, 0xf2, 0x20, 0xe1, 0x25, 0x95, 0xd0, 0x45,
-	0x85, 0x32, 0x37, 0x54, 0x42, 0x17, 0x25, 0xf5, 0x14, 0x60, 0xc6, 0xd8, 0x52, 0x21, 0xe2, 0x59,
-	0x68, 0x0e, 0x34, 0x62, 0x0b, 0xad, 0x04, 0x3e, 0xe7, 0x2c, 0x51, 0x80, 0xa5, 0x36, 0x6d, 0x0b,
-	0x4d, 0x02, 0xc7, 0x6d, 0x00, 0x99, 0x93, 0xd7, 0xe1, 0xb8, 0x05, 0x76, 0x34, 0xe5, 0xd3, 0x22,
-	0x68, 0x7c, 0xac, 0xcb, 0xd7, 0x72, 0x66, 0xc9, 0x9f, 0xa3, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0x66, 0x71, 0xa8, 0x39, 0x44, 0x05, 0x00, 0x00,
+	// 661 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x94, 0xd1, 0x6e, 0xda, 0x4a,
+	0x10, 0x86, 0x31, 0x36, 0x06, 0x0f, 0x90, 0x90, 0x49, 0x4e, 0xce, 0x0a, 0x1d, 0xe5, 0x58, 0x39,
+	0xe7, 0xc2, 0x6a, 0x25, 0xd4, 0x24, 0xea, 0x0b, 0x24, 0x25, 0x80, 0x44, 0x00, 0x6d, 0x69, 0xa5,
+	0xf4, 0x06, 0x01, 0x5e, 0xa8, 0x5b, 0xf0, 0x5a, 0xf6, 0x52, 0x89, 0x8b, 0xbe, 0x4a, 0x5f, 0x30,
+	0xea, 0x13, 0xf4, 0xa6, 0xda, 0xf5, 0xe2, 0x38, 0x55, 0x52, 0x29, 0x17, 0xbd, 0x82, 0xf9, 0xe7,
+	0xdb, 0xdf, 0x9e, 0xf1, 0xec, 0x40, 0x6d, 0xc9, 0xf8, 0x6c, 0xb3, 0x68, 0x45, 0x31, 0x17, 0x1c,
+	0xed, 0x34, 0x3a, 0xbd, 0x03, 0xb0, 0xde, 0x4c, 0xc5, 0x14, 0x11, 0xac, 0xcf, 0x6c, 0x9b, 0x10,
+	0xc3, 0x35, 0x3d, 0x87, 0xaa, 0xff, 0x78, 0x02, 0xe0, 0x07, 0x6b, 0x16, 0x26, 0x01, 0x0f, 0x13,
+	0x52, 0x74, 0x0d, 0xaf, 0x4e, 0x73, 0x0a, 0xfe, 0x03, 0x4e, 0x14, 0xb3, 0x79, 0x20, 0x23, 0x62,
+	0xaa, 0xf4, 0xbd, 0x80, 0x67, 0x70, 0x94, 0xb1, 0x93, 0x4c, 0x4e, 0x48, 0xd9, 0x35, 0xbd, 0x3a,
+	0x3d, 0xcc, 0x72, 0xa3, 0x2c, 0x25, 0x0d, 0x45, 0x3c, 0x0d, 0x93, 0x05, 0x8f, 0xd7, 0xa4, 0xe2,
+	0x9a, 0x9e, 0x41, 0xef, 0x05, 0x1c, 0x02, 0x2e, 0xd8, 0x54, 0x6c, 0x62, 0x36, 0x99, 0xf3, 0xd5,
+	0x8a, 0xcd, 0x85, 0x7c, 0xae, 0xe5, 0x1a, 0x5e, 0xf5, 0xfc, 0xa4, 0xa5, 0xcb, 0x93, 0xc5, 0xb4,
+	0xae, 0x53, 0xec, 0x2a, 0xa3, 0xba, 0x05, 0x7a, 0xb0, 0xf8, 0x55, 0xc4, 0x57, 0x50, 0xd6, 0x22,
+	0x29, 0x29, 0x97, 0xa3, 0xc7, 0x5c, 0xba, 0x05, 0xba, 0xc3, 0xf0, 0x02, 0x2a, 0x4b, 0xc6, 0xd7,
+	0x4c, 0xc4, 0x5b, 0x62, 0xab, 0x23, 0x7f, 0x3d, 0x38, 0xd2, 0xd1, 0xc9, 0x6e, 0x81, 0x66, 0x60,
+	0xf3, 0xce, 0x80, 0xb2, 0xf6, 0xc2, 0xb3, 0x9c, 0x81, 0xf1, 0x1b, 0x83, 0xfb, 0xe3, 0xd8, 0x80,
+	0x62, 0xe0, 0x93, 0xaa, 0x6b, 0x78, 0x4e, 0xb7, 0x40, 0x8b, 0x81, 0x8f, 0x7f, 0x83, 0x1d, 0x84,
+	0x62, 0x12, 0xf8, 0xa4, 0xe6, 0x1a, 0x1e, 0x76, 0x0b, 0xb4, 0x14, 0x84, 0xa2, 0xe7, 0xe3, 0x0b,
+	0xb0, 0xbf, 0x4c, 0x57, 0x1b, 0x96, 0x90, 0xba, 0x6b, 0x7a, 0xd5, 0x73, 0x7c, 0xe0, 0xfd, 0x5e,
+	0xa6, 0xa8, 0x26, 0xe4, 0xc7, 0x8d, 0x62, 0x1e, 0xb1, 0x58, 0x04, 0x2c, 0x21, 0x7b, 0xea, 0xa3,
+	0xe4, 0x14, 0x7c, 0x09, 0x07, 0xf3, 0x4d, 0x22, 0xf8, 0x7a, 0x92, 0xc3, 0xf6, 0x15, 0xd6, 0x48,
+	0x13, 0xa3, 0x4c, 0xbf, 0x74, 0xa0, 0x1c, 0xf8, 0x13, 0xb1, 0x8d, 0x58, 0xf3, 0x47, 0x11, 0x2a,
+	0xbb, 0x2a, 0xb0, 0x05, 0x96, 0x14, 0x55, 0xa9, 0x7b, 0xe7, 0xcd, 0x47, 0x4b, 0x6d, 0x8d, 0xb7,
+	0x11, 0xa3, 0x8a, 0x43, 0x02, 0xe5, 0x15, 0x0b, 0x97, 0xe2, 0xa3, 0x1c, 0x37, 0xf9, 0xa8, 0x5d,
+	0x88, 0xc7, 0x60, 0xcf, 0x39, 0x8f, 0xfd, 0x84, 0x98, 0xae, 0xe9, 0x21, 0xd5, 0x11, 0xbe, 0x06,
+	0xd0, 0x9d, 0x92, 0xef, 0x67, 0xb9, 0xe6, 0xd3, 0x2d, 0xcd, 0x81, 0xcf, 0xea, 0xd4, 0x73, 0x3a,
+	0x71, 0xfa, 0x15, 0x2c, 0x59, 0x0f, 0x3a, 0x50, 0x1a, 0x0d, 0x7b, 0x83, 0x71, 0xa3, 0x80, 0x7b,
+	0x00, 0x37, 0xef, 0xfa, 0xe3, 0x5e, 0x1a, 0x1b, 0x32, 0xee, 0xf7, 0x06, 0xed, 0xb7, 0x63, 0xda,
+	0x1b, 0x74, 0x1a, 0x45, 0x3c, 0x84, 0x7d, 0x95, 0xcf, 0x89, 0x26, 0x56, 0xa1, 0x3c, 0x1a, 0xf6,
+	0x6f, 0x3b, 0xc3, 0x41, 0xc3, 0xc2, 0x06, 0xd4, 0xb4, 0x43, 0xaa, 0x94, 0xf0, 0x18, 0xb0, 0xd3,
+	0x1e, 0xde, 0xb4, 0xc7, 0xf4, 0xf6, 0x6a, 0xd8, 0xef, 0xb7, 0xaf, 0xc6, 0xbd, 0xe1, 0xa0, 0x61,
+	0x37, 0xbf, 0x19, 0x70, 0x70, 0xfd, 0xc8, 0xa0, 0x57, 0xf4, 0x04, 0xa7, 0x17, 0xfc, 0x89, 0x49,
+	0xa7, 0x19, 0xf5, 0xc7, 0xfa, 0xd3, 0xfc, 0x6e, 0x40, 0x49, 0x1d, 0xc7, 0xff, 0xa0, 0x96, 0x88,
+	0x38, 0x08, 0x97, 0x13, 0xe5, 0x43, 0x0c, 0x3d, 0xe1, 0xd5, 0x54, 0xcd, 0x20, 0x9f, 0x6f, 0x66,
+	0x2b, 0xa6, 0x21, 0xb9, 0x84, 0x0c, 0x09, 0xa5, 0x6a, 0x0a, 0xfd, 0x0f, 0xf5, 0x88, 0x27, 0x13,
+	0x79, 0x27, 0x52, 0x4a, 0xee, 0x22, 0x4b, 0x52, 0x11, 0x4f, 0x7a, 0xa1, 0xc8, 0xa8, 0x90, 0x2d,
+	0x73, 0x94, 0xb5, 0xa3, 0x42, 0xb6, 0xcc, 0xa8, 0x7f, 0x01, 0x66, 0x9c, 0xaf, 0x34, 0x22, 0xd7,
+	0x42, 0xa5, 0x5b, 0xa0, 0x8e, 0xd4, 0x32, 0xe0, 0x53, 0xc2, 0x43, 0x0d, 0xd8, 0xfa, 0xa5, 0x1d,
+	0xa9, 0x29, 0xe0, 0xb2, 0x06, 0xa0, 0x72, 0xea, 0x3a, 0x5c, 0x56, 0xc1, 0xf1, 0xa7, 0x62, 0x9a,
+	0x06, 0xe5, 0x0f, 0x25, 0xb5, 0x7e, 0x67, 0xb6, 0xfa, 0xb9, 0xf8, 0x39, 0x00, 0x0b, 0x3f, 0x07,
+	0xb4, 0x95, 0x05, 0x00, 0x00,
 }
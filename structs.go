@@ -0,0 +1,318 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	gomath "math"
+	"reflect"
+	"strings"
+
+	"github.com/cairnapp/go-geobuf/pkg/decode"
+	"github.com/cairnapp/go-geobuf/pkg/encode"
+	"github.com/cairnapp/go-geobuf/pkg/geojson"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+	"github.com/cairnapp/go-geobuf/proto"
+)
+
+var geometryType = reflect.TypeOf((*geometry.Geometry)(nil)).Elem()
+
+// structSchema maps the fields of a struct type onto geobuf keys. It follows
+// encoding/json conventions: exported fields are keyed by their
+// `geobuf:"name"` tag or their Go name, "-" skips a field and "omitempty"
+// skips zero values. One field may be tagged `geobuf:",geometry"` and one
+// `geobuf:",id"`; they become the feature's geometry and id.
+type structSchema struct {
+	fields   []structField
+	geometry int
+	id       int
+}
+
+type structField struct {
+	index     int
+	key       string
+	omitEmpty bool
+}
+
+func schemaOf(t reflect.Type) (*structSchema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("geobuf: %s is not a struct", t)
+	}
+
+	schema := &structSchema{geometry: -1, id: -1}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := field.Tag.Get("geobuf")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		key := parts[0]
+		if key == "" {
+			key = field.Name
+		}
+
+		sf := structField{index: i, key: key}
+		role := ""
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "omitempty":
+				sf.omitEmpty = true
+			case "geometry", "id":
+				role = opt
+			default:
+				return nil, fmt.Errorf("geobuf: unknown tag option %q on field %s", opt, field.Name)
+			}
+		}
+
+		switch role {
+		case "geometry":
+			if schema.geometry >= 0 {
+				return nil, fmt.Errorf("geobuf: %s has more than one geometry field", t)
+			}
+			if field.Type != geometryType && !field.Type.Implements(geometryType) {
+				return nil, fmt.Errorf("geobuf: geometry field %s is a %s, not a geometry", field.Name, field.Type)
+			}
+			schema.geometry = i
+		case "id":
+			if schema.id >= 0 {
+				return nil, fmt.Errorf("geobuf: %s has more than one id field", t)
+			}
+			schema.id = i
+		default:
+			schema.fields = append(schema.fields, sf)
+		}
+	}
+	return schema, nil
+}
+
+func (s *structSchema) keys() []string {
+	keys := make([]string, len(s.fields))
+	for i, field := range s.fields {
+		keys[i] = field.key
+	}
+	return keys
+}
+
+func (s *structSchema) feature(v reflect.Value) *geojson.Feature {
+	feature := geojson.NewFeature(nil)
+	if s.geometry >= 0 {
+		if geo := v.Field(s.geometry); !isNil(geo) {
+			feature.Geometry = geo.Interface().(geometry.Geometry)
+		}
+	}
+	if s.id >= 0 {
+		if id := v.Field(s.id); !isNil(id) {
+			feature.ID = reflect.Indirect(id).Interface()
+		}
+	}
+
+	for _, field := range s.fields {
+		val := v.Field(field.index)
+		if isNil(val) || field.omitEmpty && val.IsZero() {
+			continue
+		}
+		feature.Properties[field.key] = val.Interface()
+	}
+	return feature
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// structType returns the struct type behind T, which may be a struct or a
+// pointer to one.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// EncodeStructs encodes a slice of structs as a feature collection, see
+// structSchema for how fields are mapped. The key table comes from the
+// struct's fields, and precision is inferred as Encode does unless
+// overridden by opts.
+func EncodeStructs[T any](items []T, opts ...encode.EncodingOption) (*proto.Data, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, err := schemaOf(structType(t))
+	if err != nil {
+		return nil, err
+	}
+
+	collection := geojson.NewFeatureCollection()
+	for _, item := range items {
+		v := reflect.ValueOf(item)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, fmt.Errorf("geobuf: can't encode a nil %s", t)
+			}
+			v = v.Elem()
+		}
+		collection.Append(schema.feature(v))
+	}
+
+	keys := encode.NewKeyStoreWithKeys(schema.keys())
+	opts = append([]encode.EncodingOption{
+		encode.WithKeyStore(keys),
+		encode.FromAnalysis(collection),
+	}, opts...)
+	return EncodeWithOptions(collection, opts...)
+}
+
+// DecodeInto decodes a feature or feature collection into a slice of
+// structs, appending one item per feature. Properties without a matching
+// field are ignored.
+func DecodeInto[T any](msg *proto.Data, out *[]T) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, err := schemaOf(structType(t))
+	if err != nil {
+		return err
+	}
+
+	decoded, err := DecodeWithOptions(msg, decode.WithInt64Numbers(), decode.WithRawJSON())
+	if err != nil {
+		return err
+	}
+
+	var features []*geojson.Feature
+	switch typed := decoded.(type) {
+	case *geojson.FeatureCollection:
+		features = typed.Features
+	case *geojson.Feature:
+		features = []*geojson.Feature{typed}
+	default:
+		return fmt.Errorf("geobuf: can't decode a geometry into %s", t)
+	}
+
+	items := *out
+	for i, feature := range features {
+		item := reflect.New(structType(t))
+		if err := schema.fill(item.Elem(), feature); err != nil {
+			return &decode.FeatureError{Index: i, Err: err}
+		}
+		if t.Kind() == reflect.Ptr {
+			items = append(items, item.Interface().(T))
+		} else {
+			items = append(items, item.Elem().Interface().(T))
+		}
+	}
+	*out = items
+	return nil
+}
+
+func (s *structSchema) fill(v reflect.Value, feature *geojson.Feature) error {
+	if s.geometry >= 0 && feature.Geometry != nil {
+		field := v.Field(s.geometry)
+		geo := reflect.ValueOf(feature.Geometry)
+		if !geo.Type().AssignableTo(field.Type()) {
+			return &decode.FieldError{
+				Field: "geometry",
+				Err:   fmt.Errorf("%s can't be assigned to %s", geo.Type(), field.Type()),
+			}
+		}
+		field.Set(geo)
+	}
+	if s.id >= 0 && feature.ID != nil {
+		if err := setField(v.Field(s.id), feature.ID); err != nil {
+			return &decode.FieldError{Field: "id", Err: err}
+		}
+	}
+
+	for _, field := range s.fields {
+		val, ok := feature.Properties[field.key]
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(field.index), val); err != nil {
+			return &decode.FieldError{Field: field.key, Err: err}
+		}
+	}
+	return nil
+}
+
+// setField assigns a decoded value to a struct field, converting numbers
+// between widths as long as they don't overflow.
+func setField(field reflect.Value, val interface{}) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if raw, ok := val.(json.RawMessage); ok {
+		return json.Unmarshal(raw, field.Addr().Interface())
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch typed := val.(type) {
+		case int64:
+			n = typed
+		case uint64:
+			if typed > gomath.MaxInt64 {
+				return fmt.Errorf("%d overflows %s", typed, field.Type())
+			}
+			n = int64(typed)
+		default:
+			return fmt.Errorf("%T can't be assigned to %s", val, field.Type())
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, field.Type())
+		}
+		field.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch typed := val.(type) {
+		case int64:
+			if typed < 0 {
+				return fmt.Errorf("%d overflows %s", typed, field.Type())
+			}
+			n = uint64(typed)
+		case uint64:
+			n = typed
+		default:
+			return fmt.Errorf("%T can't be assigned to %s", val, field.Type())
+		}
+		if field.OverflowUint(n) {
+			return fmt.Errorf("%d overflows %s", n, field.Type())
+		}
+		field.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch typed := val.(type) {
+		case int64:
+			field.SetFloat(float64(typed))
+		case uint64:
+			field.SetFloat(float64(typed))
+		case float64:
+			field.SetFloat(typed)
+		default:
+			return fmt.Errorf("%T can't be assigned to %s", val, field.Type())
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(val)
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+	if v.Kind() == field.Kind() && v.Type().ConvertibleTo(field.Type()) {
+		field.Set(v.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("%T can't be assigned to %s", val, field.Type())
+}
@@ -0,0 +1,142 @@
+package geobuf_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/cairnapp/go-geobuf"
+	"github.com/cairnapp/go-geobuf/pkg/decode"
+	"github.com/cairnapp/go-geobuf/pkg/geometry"
+)
+
+type parcelStatus string
+
+type parcel struct {
+	ID       int64             `geobuf:",id"`
+	Shape    geometry.Geometry `geobuf:",geometry"`
+	Name     string            `geobuf:"name"`
+	Area     float32           `geobuf:"area"`
+	Floors   uint8             `geobuf:"floors,omitempty"`
+	Height   *int16            `geobuf:"height"`
+	Status   parcelStatus      `geobuf:"status"`
+	Owners   []string          `geobuf:"owners,omitempty"`
+	Vacant   bool
+	Internal string `geobuf:"-"`
+	private  string
+}
+
+type point struct {
+	Name     string         `geobuf:"name"`
+	Location geometry.Point `geobuf:",geometry"`
+}
+
+func int16Ptr(val int16) *int16 {
+	return &val
+}
+
+func TestStructsRoundTrip(t *testing.T) {
+	items := []parcel{
+		{
+			ID: 1,
+			Shape: geometry.Polygon([]geometry.Ring{
+				geometry.Ring([]geometry.Point{
+					geometry.Point([]float64{124.123, 234.456}),
+					geometry.Point([]float64{345.567, 456.678}),
+					geometry.Point([]float64{124.123, 234.456}),
+				}),
+			}),
+			Name:   "first",
+			Area:   12.5,
+			Floors: 3,
+			Height: int16Ptr(-4),
+			Status: "active",
+			Owners: []string{"A", "B"},
+			Vacant: true,
+		},
+		{
+			ID:    2,
+			Shape: geometry.Point([]float64{1, 2}),
+			Name:  "second",
+		},
+	}
+
+	encoded, err := EncodeStructs(items)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	expectedKeys := []string{"Vacant", "area", "floors", "height", "name", "owners", "status"}
+	if !reflect.DeepEqual(expectedKeys, encoded.Keys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, encoded.Keys)
+	}
+
+	decoded := []parcel{}
+	if err := DecodeInto(encoded, &decoded); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(items, decoded) {
+		t.Errorf("Expected %+v, got %+v", items, decoded)
+	}
+}
+
+func TestStructsPointers(t *testing.T) {
+	items := []*point{
+		{Name: "a", Location: geometry.Point([]float64{1.5, 2.5})},
+	}
+
+	encoded, err := EncodeStructs(items)
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	decoded := []*point{}
+	if err := DecodeInto(encoded, &decoded); err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+	if !reflect.DeepEqual(items, decoded) {
+		t.Errorf("Expected %+v, got %+v", items, decoded)
+	}
+}
+
+func TestDecodeIntoMismatch(t *testing.T) {
+	encoded, err := EncodeStructs([]parcel{{
+		ID: 1,
+		Shape: geometry.LineString([]geometry.Point{
+			geometry.Point([]float64{1, 2}),
+			geometry.Point([]float64{3, 4}),
+		}),
+		Name: "a",
+	}})
+	if err != nil {
+		t.Fatalf("Got unexpected error %s!", err)
+	}
+
+	decoded := []point{}
+	err = DecodeInto(encoded, &decoded)
+	fieldErr := &decode.FieldError{}
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "geometry" {
+		t.Errorf("Expected a geometry field error, got %v", err)
+	}
+}
+
+func TestEncodeStructsInvalid(t *testing.T) {
+	type twoGeometries struct {
+		A geometry.Point `geobuf:",geometry"`
+		B geometry.Point `geobuf:",geometry"`
+	}
+	if _, err := EncodeStructs([]twoGeometries{{}}); err == nil {
+		t.Errorf("Expected an error for two geometry fields")
+	}
+
+	type notGeometry struct {
+		A string `geobuf:",geometry"`
+	}
+	if _, err := EncodeStructs([]notGeometry{{}}); err == nil {
+		t.Errorf("Expected an error for a geometry field that isn't a geometry")
+	}
+
+	if _, err := EncodeStructs([]int{1}); err == nil {
+		t.Errorf("Expected an error for a non-struct type")
+	}
+}
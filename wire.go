@@ -0,0 +1,156 @@
+package geobuf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	protobuf "github.com/golang/protobuf/proto"
+)
+
+// Field numbers from geobuf.proto that are read and written by hand when
+// streaming.
+const (
+	dataKeysField              = 1
+	dataDimensionsField        = 2
+	dataPrecisionField         = 3
+	dataFeatureCollectionField = 4
+	dataFeatureField           = 5
+	dataGeometryField          = 6
+	dataPrecisionsField        = 7
+	dataTransformField         = 8
+	collectionFeaturesField    = 1
+)
+
+func appendTag(buf []byte, field int, wireType int) []byte {
+	return append(buf, protobuf.EncodeVarint(uint64(field)<<3|uint64(wireType))...)
+}
+
+func appendBytesField(buf []byte, field int, value []byte) []byte {
+	buf = appendTag(buf, field, protobuf.WireBytes)
+	buf = append(buf, protobuf.EncodeVarint(uint64(len(value)))...)
+	return append(buf, value...)
+}
+
+// appendPaddedVarint appends v as a varint padded to the full 10 bytes a
+// uint64 can take, so that it can be overwritten in place once v is known.
+// Parsers read the extra continuation bytes like any other varint.
+func appendPaddedVarint(buf []byte, v uint64) []byte {
+	for i := 0; i < binary.MaxVarintLen64-1; i++ {
+		buf = append(buf, byte(v&0x7f|0x80))
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+// wireReader reads protobuf wire format primitives, keeping track of how many
+// bytes it has consumed so that embedded messages can be walked in place.
+type wireReader struct {
+	r      *bufio.Reader
+	offset uint64
+}
+
+func newWireReader(r io.Reader) *wireReader {
+	return &wireReader{r: bufio.NewReader(r)}
+}
+
+func (w *wireReader) ReadByte() (byte, error) {
+	b, err := w.r.ReadByte()
+	if err == nil {
+		w.offset++
+	}
+	return b, err
+}
+
+func (w *wireReader) readVarint() (uint64, error) {
+	start := w.offset
+	val, err := binary.ReadUvarint(w)
+	if err == io.EOF && w.offset != start {
+		err = io.ErrUnexpectedEOF
+	}
+	return val, err
+}
+
+func (w *wireReader) readFixed64() (uint64, error) {
+	var buf [8]byte
+	n, err := io.ReadFull(w.r, buf[:])
+	w.offset += uint64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return binary.LittleEndian.Uint64(buf[:]), err
+}
+
+// readTag returns io.EOF only when the stream ends cleanly between fields.
+func (w *wireReader) readTag() (int, int, error) {
+	tag, err := w.readVarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(tag >> 3), int(tag & 7), nil
+}
+
+// readBytes reads a length delimited field, refusing lengths beyond limit.
+func (w *wireReader) readBytes(limit uint64) ([]byte, error) {
+	length, err := w.readLength(limit)
+	if err != nil {
+		return nil, err
+	}
+	// The buffer grows as the bytes arrive rather than taking the length on
+	// trust, so a stream claiming a huge field doesn't allocate it up front
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, w.r, int64(length))
+	w.offset += uint64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+func (w *wireReader) readLength(limit uint64) (uint64, error) {
+	length, err := w.readVarint()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, err
+	}
+	if length > limit {
+		return 0, fmt.Errorf("geobuf: field length %d exceeds the %d bytes available", length, limit)
+	}
+	return length, nil
+}
+
+// skip discards the value of a field with the given wire type.
+func (w *wireReader) skip(wireType int, limit uint64) error {
+	var n uint64
+	switch wireType {
+	case protobuf.WireVarint:
+		_, err := w.readVarint()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	case protobuf.WireFixed64:
+		n = 8
+	case protobuf.WireFixed32:
+		n = 4
+	case protobuf.WireBytes:
+		length, err := w.readLength(limit)
+		if err != nil {
+			return err
+		}
+		n = length
+	default:
+		return fmt.Errorf("geobuf: unsupported wire type %d", wireType)
+	}
+
+	skipped, err := w.r.Discard(int(n))
+	w.offset += uint64(skipped)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}